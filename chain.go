@@ -19,11 +19,14 @@ type Chain struct {
 
 // NewChain creates new Chain. Provided context manages whole Chain. Close
 // Context is equivalent to call Chain.Close().
+// Middlewares from Context are applied to all components. See WithMiddleware.
+// Health of components which implement Checker is checked. See
+// WithHealthCheck.
 func NewChain(ctx context.Context, components ...Component) (c *Chain) {
 	c = &Chain{
-		components: components,
+		components: applyMiddlewares(ctx, applyHealthChecks(ctx, injectLoggers(ctx, components))),
 	}
-	c.composite = newComposite(ctx, func(control *compositeControl) {
+	c.composite = newComposite(ctx, "chain", func(control *compositeControl) {
 		_, cancel := context.WithCancel(context.Background())
 		c.buildLink(cancel, c.components)
 	})
@@ -31,55 +34,96 @@ func NewChain(ctx context.Context, components ...Component) (c *Chain) {
 }
 
 func (c *Chain) buildLink(ascendantCancel context.CancelFunc, tail []Component) {
-	if len(tail) == 0 {
-		// supervise last chunk
+	if len(tail) == 0 || c.control.ctx.Err() != nil {
+		// supervise last chunk or stop open if chain is closed
 		go func() {
 			<-c.control.ctx.Done()
+			c.control.report.initiate("", "", nil)
 			ascendantCancel()
 		}()
 		return
 	}
 	component := tail[0]
+	index := len(c.components) - len(tail)
+	name := nameOf(component, index)
 
-	if openErr := component.Open(); openErr != nil {
+	var openErr error
+	ctx, cancel := context.WithCancel(context.Background())
+	c.control.do(name, func() {
+		trace := c.control.trace.child(component, name)
+		openSpan := trace.start(PhaseOpen)
+		openTime := c.control.clock.Now()
+		openErr = openComponent(c.control.ctx, component)
+		openSpan.end(openErr)
+		if openErr != nil {
+			return
+		}
+		c.control.log.log("open", name, PhaseOpen, c.control.since(openTime), nil)
+		c.superviseLink(ctx, cancel, ascendantCancel, index, name, component, trace)
+	})
+	if openErr != nil {
+		c.control.log.log("fail", name, PhaseOpen, 0, openErr)
 		c.control.openError.set(openErr)
+		c.control.report.initiate(name, PhaseOpen, openErr)
+		cancel()
 		ascendantCancel()
 		c.control.cancelFunc()
 		return
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
+	c.buildLink(cancel, tail[1:])
+}
+
+// superviseLink supervises close and exit of opened link
+func (c *Chain) superviseLink(ctx context.Context, cancel, ascendantCancel context.CancelFunc, index int, name string, component Component, trace *childTrace) {
 	var waitExited uint32
 
 	// supervise close
-	c.control.closeWg.Add(1)
+	closed := c.control.addCloser()
 	go func() {
-		defer c.control.closeWg.Done()
+		defer closed()
 		<-ctx.Done()
 		if atomic.CompareAndSwapUint32(&waitExited, 0, 1) {
-			if closeErr := component.Close(); closeErr != nil {
+			c.control.report.closing(index)
+			closeTime := c.control.clock.Now()
+			closeSpan := trace.start(PhaseClose)
+			closeErr := component.Close()
+			closeSpan.end(closeErr)
+			if closeErr != nil {
 				c.control.closeError.set(closeErr)
 			}
+			c.control.report.done(index, name, PhaseClose, closeErr)
+			c.control.log.log("close", name, PhaseClose, c.control.since(closeTime), closeErr)
 		}
 	}()
 
 	// supervise wait
 	c.control.waitWg.Add(1)
+	openTime := c.control.clock.Now()
+	waitSpan := trace.start(PhaseWait)
 	go func() {
 		defer c.control.waitWg.Done()
-		if waitErr := component.Wait(); waitErr != nil {
+		waitErr := component.Wait()
+		waitSpan.end(waitErr)
+		if waitErr != nil {
 			c.control.waitError.set(waitErr)
 		}
+		c.control.report.done(index, name, PhaseWait, waitErr)
+		c.control.log.log("exit", name, PhaseWait, c.control.since(openTime), waitErr)
 		atomic.CompareAndSwapUint32(&waitExited, 0, 1)
 		select {
 		case <-c.control.ctx.Done(): // normal shutdown
 		default: // abnormal shutdown we need close context and wait for descendants
+			c.control.log.log("fail", name, PhaseWait, 0, waitErr)
+			c.control.report.initiate(name, PhaseWait, waitErr)
 			c.control.cancelFunc()
 			<-ctx.Done()
 		}
 		ascendantCancel()
 		cancel()
 	}()
+}
 
-	c.buildLink(cancel, tail[1:])
+func (c *Chain) children() (components []Component) {
+	return c.components
 }
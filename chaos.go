@@ -0,0 +1,197 @@
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrChaos is returned by Components wrapped by Chaos on injected faults
+	ErrChaos = errors.New("chaos fault")
+)
+
+// ChaosConfig configures faults injected by Chaos. All probabilities are in
+// [0, 1] range.
+type ChaosConfig struct {
+
+	// Seed is random generator seed. Chaos with equal seeds injects equal
+	// faults to Components wrapped in the same order.
+	Seed int64
+
+	// OpenFailure is probability of failed Open()
+	OpenFailure float64
+
+	// EarlyExit is probability of Wait() return before Close()
+	EarlyExit float64
+
+	// MaxExitDelay is maximum delay between Open() and early exit. Default is
+	// one second.
+	MaxExitDelay time.Duration
+
+	// CloseHang is probability of hang in Close()
+	CloseHang float64
+
+	// Hang is duration of hang in Close(). Zero Hang blocks Close() until
+	// Chaos is closed.
+	Hang time.Duration
+
+	// Panic is probability of panic in one of Component methods
+	Panic float64
+}
+
+// Chaos injects faults into wrapped Components. Use Chaos to validate
+// supervisor trees configuration.
+type Chaos struct {
+	config    ChaosConfig
+	clock     Clock
+	closeOnce sync.Once
+	released  chan struct{} // closed by Close()
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// NewChaos returns new Chaos. Chaos uses Clock from given Context.
+func NewChaos(ctx context.Context, config ChaosConfig) (c *Chaos) {
+	if config.MaxExitDelay <= 0 {
+		config.MaxExitDelay = time.Second
+	}
+	return &Chaos{
+		config:   config,
+		clock:    ClockFrom(ctx),
+		released: make(chan struct{}),
+		rand:     rand.New(rand.NewSource(config.Seed)),
+	}
+}
+
+// Close releases all hanging Close() calls of wrapped Components
+func (c *Chaos) Close() {
+	c.closeOnce.Do(func() {
+		close(c.released)
+	})
+}
+
+// Wrap returns Component which injects faults into given Component. Faults
+// are chosen on Wrap call.
+func (c *Chaos) Wrap(component Component) Component {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	res := &chaosComponent{
+		Component: component,
+		clock:     c.clock,
+		hang:      c.config.Hang,
+		released:  c.released,
+		closed:    make(chan struct{}),
+		exit:      make(chan struct{}),
+	}
+	res.openFailure = c.rand.Float64() < c.config.OpenFailure
+	if c.rand.Float64() < c.config.EarlyExit {
+		res.exitDelay = time.Duration(c.rand.Int63n(int64(c.config.MaxExitDelay))) + 1
+	}
+	res.closeHang = c.rand.Float64() < c.config.CloseHang
+	if c.rand.Float64() < c.config.Panic {
+		res.panicPhase = []Phase{PhaseOpen, PhaseClose, PhaseWait}[c.rand.Intn(3)]
+	}
+	return res
+}
+
+type chaosComponent struct {
+	Component
+	clock    Clock
+	hang     time.Duration
+	released chan struct{} // releases hang
+
+	openFailure bool
+	exitDelay   time.Duration // zero if no early exit
+	closeHang   bool
+	panicPhase  Phase
+
+	closeOnce sync.Once // guards Close() of wrapped Component
+	closeErr  error
+	closed    chan struct{}
+	exit      chan struct{} // closed on early exit
+}
+
+// Unwrap returns wrapped Component
+func (c *chaosComponent) Unwrap() Component {
+	return c.Component
+}
+
+func (c *chaosComponent) Open() (err error) {
+	c.maybePanic(PhaseOpen)
+	if c.openFailure {
+		return ErrChaos
+	}
+	if err = c.Component.Open(); err != nil || c.exitDelay == 0 {
+		return err
+	}
+	timer := c.clock.NewTimer(c.exitDelay)
+	go func() {
+		select {
+		case <-timer.C():
+			close(c.exit)
+		case <-c.closed:
+			timer.Stop()
+		}
+	}()
+	return nil
+}
+
+func (c *chaosComponent) Close() (err error) {
+	c.maybePanic(PhaseClose)
+	if c.closeHang {
+		c.hangClose()
+	}
+	return c.closeComponent()
+}
+
+// hangClose blocks for hang duration or until Chaos is closed
+func (c *chaosComponent) hangClose() {
+	if c.hang == 0 {
+		<-c.released
+		return
+	}
+	timer := c.clock.NewTimer(c.hang)
+	defer timer.Stop()
+	select {
+	case <-timer.C():
+	case <-c.released:
+	}
+}
+
+// closeComponent closes wrapped Component once
+func (c *chaosComponent) closeComponent() (err error) {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		c.closeErr = c.Component.Close()
+	})
+	return c.closeErr
+}
+
+func (c *chaosComponent) Wait() (err error) {
+	c.maybePanic(PhaseWait)
+	if c.openFailure {
+		return nil
+	}
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- c.Component.Wait()
+	}()
+	select {
+	case err = <-errChan:
+		return err
+	case <-c.exit:
+		c.closeComponent()
+		<-errChan
+		return ErrChaos
+	}
+}
+
+func (c *chaosComponent) maybePanic(phase Phase) {
+	if c.panicPhase == phase {
+		panic("chaos: panic in " + string(phase))
+	}
+}
@@ -0,0 +1,125 @@
+package supervisor_test
+
+import (
+	"context"
+	"github.com/akaspin/supervisor"
+	"github.com/akaspin/supervisor/supervisortest"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestChaos_Wrap(t *testing.T) {
+	t.Run("open failure", func(t *testing.T) {
+		chaos := supervisor.NewChaos(context.Background(), supervisor.ChaosConfig{
+			OpenFailure: 1,
+		})
+		c1 := supervisortest.NewComponent("1", nil)
+		c2 := supervisortest.NewComponent("2", nil)
+		sv := supervisor.NewChain(context.Background(), c1, chaos.Wrap(c2))
+		assert.Equal(t, supervisor.ErrChaos, sv.Open())
+		assert.NoError(t, sv.Wait())
+		c1.Events().Assert(t, "open", "close", "wait")
+		c2.Events().Assert(t)
+	})
+	t.Run("early exit", func(t *testing.T) {
+		clock := supervisortest.NewClock(time.Now())
+		ctx := supervisor.WithClock(context.Background(), clock)
+		chaos := supervisor.NewChaos(ctx, supervisor.ChaosConfig{
+			EarlyExit:    1,
+			MaxExitDelay: time.Second,
+		})
+		c1 := supervisortest.NewComponent("1", nil)
+		c2 := supervisortest.NewComponent("2", nil)
+		sv := supervisor.NewGroup(ctx, c1, chaos.Wrap(c2))
+		assert.NoError(t, sv.Open())
+		clock.BlockUntil(1)
+		clock.Advance(time.Second)
+		assert.Equal(t, supervisor.ErrChaos, sv.Wait())
+		c1.Events().Assert(t, "open", "close", "wait")
+		c2.Events().Assert(t, "open", "close", "wait")
+		assert.Equal(t, "2", sv.ShutdownReport().Initiator)
+	})
+	t.Run("close hang", func(t *testing.T) {
+		clock := supervisortest.NewClock(time.Now())
+		ctx := supervisor.WithClock(context.Background(), clock)
+		chaos := supervisor.NewChaos(ctx, supervisor.ChaosConfig{
+			CloseHang: 1,
+			Hang:      time.Minute,
+		})
+		c1 := supervisortest.NewComponent("1", nil)
+		wrapped := chaos.Wrap(c1)
+		assert.NoError(t, wrapped.Open())
+		closeChan := make(chan struct{})
+		go func() {
+			defer close(closeChan)
+			assert.NoError(t, wrapped.Close())
+		}()
+		clock.BlockUntil(1)
+		c1.Events().Assert(t, "open")
+		clock.Advance(time.Minute)
+		<-closeChan
+		assert.NoError(t, wrapped.Wait())
+		c1.Events().Assert(t, "open", "close", "wait")
+	})
+	t.Run("close hang released", func(t *testing.T) {
+		chaos := supervisor.NewChaos(context.Background(), supervisor.ChaosConfig{
+			CloseHang: 1,
+		})
+		c1 := supervisortest.NewComponent("1", nil)
+		wrapped := chaos.Wrap(c1)
+		assert.NoError(t, wrapped.Open())
+		closeChan := make(chan struct{})
+		go func() {
+			defer close(closeChan)
+			assert.NoError(t, wrapped.Close())
+		}()
+		select {
+		case <-closeChan:
+			t.Fatal("close is not hanged")
+		case <-time.After(time.Millisecond * 50):
+		}
+		chaos.Close()
+		<-closeChan
+		assert.NoError(t, wrapped.Wait())
+		c1.Events().Assert(t, "open", "close", "wait")
+	})
+	t.Run("panic", func(t *testing.T) {
+		chaos := supervisor.NewChaos(context.Background(), supervisor.ChaosConfig{
+			Panic: 1,
+		})
+		for i := 0; i < 10; i++ {
+			c1 := supervisortest.NewComponent("1", nil)
+			c1.Exit()
+			wrapped := chaos.Wrap(c1)
+			var panics int
+			for _, fn := range []func() error{wrapped.Open, wrapped.Close, wrapped.Wait} {
+				func() {
+					defer func() {
+						if recover() != nil {
+							panics++
+						}
+					}()
+					fn()
+				}()
+			}
+			assert.Equal(t, 1, panics)
+		}
+	})
+	t.Run("seed", func(t *testing.T) {
+		config := supervisor.ChaosConfig{
+			Seed:        42,
+			OpenFailure: 0.5,
+		}
+		var res [2][]error
+		for i := range res {
+			chaos := supervisor.NewChaos(context.Background(), config)
+			for j := 0; j < 20; j++ {
+				res[i] = append(res[i], chaos.Wrap(supervisortest.NewComponent("1", nil)).Open())
+			}
+		}
+		assert.Equal(t, res[0], res[1])
+		assert.Contains(t, res[0], supervisor.ErrChaos)
+		assert.Contains(t, res[0], nil)
+	})
+}
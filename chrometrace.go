@@ -0,0 +1,133 @@
+package supervisor
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+/*
+ChromeTrace is SpanExporter which writes spans in Chrome trace event format.
+Open written trace in chrome://tracing or https://ui.perfetto.dev. Each
+supervised component is displayed as separate thread named by component path.
+
+ChromeTrace collects spans in memory and writes them on Close().
+*/
+type ChromeTrace struct {
+	w      io.Writer
+	closer io.Closer
+
+	mu     sync.Mutex
+	start  time.Time
+	tids   map[string]int
+	events []chromeTraceEvent
+	closed bool
+}
+
+// NewChromeTrace returns ChromeTrace which writes trace to given Writer
+func NewChromeTrace(w io.Writer) (t *ChromeTrace) {
+	return &ChromeTrace{
+		w:    w,
+		tids: map[string]int{},
+	}
+}
+
+// CreateChromeTrace creates file with given name and returns ChromeTrace
+// which writes trace to created file. File is closed on Close().
+func CreateChromeTrace(name string) (t *ChromeTrace, err error) {
+	f, err := os.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	t = NewChromeTrace(f)
+	t.closer = f
+	return t, nil
+}
+
+type chromeTraceEvent struct {
+	Name  string            `json:"name"`
+	Cat   string            `json:"cat,omitempty"`
+	Ph    string            `json:"ph"`
+	Ts    int64             `json:"ts"`
+	Dur   int64             `json:"dur,omitempty"`
+	Pid   int               `json:"pid"`
+	Tid   int               `json:"tid"`
+	Args  map[string]string `json:"args,omitempty"`
+	start time.Time
+}
+
+// ExportSpan collects given span
+func (t *ChromeTrace) ExportSpan(span Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return
+	}
+	tid, ok := t.tids[span.Path]
+	if !ok {
+		tid = len(t.tids) + 1
+		t.tids[span.Path] = tid
+		t.events = append(t.events, chromeTraceEvent{
+			Name: "thread_name",
+			Ph:   "M",
+			Pid:  1,
+			Tid:  tid,
+			Args: map[string]string{"name": span.Path},
+		})
+	}
+	if t.start.IsZero() || span.Start.Before(t.start) {
+		t.start = span.Start
+	}
+	args := map[string]string{
+		"id":     formatSpanID(span.ID),
+		"parent": formatSpanID(span.Parent),
+	}
+	if span.Err != nil {
+		args["error"] = span.Err.Error()
+	}
+	t.events = append(t.events, chromeTraceEvent{
+		Name:  string(span.Phase) + " " + span.Component,
+		Cat:   string(span.Phase),
+		Ph:    "X",
+		Dur:   span.Duration().Microseconds(),
+		Pid:   1,
+		Tid:   tid,
+		Args:  args,
+		start: span.Start,
+	})
+}
+
+// Close writes collected spans. Spans exported after Close() are ignored.
+func (t *ChromeTrace) Close() (err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return nil
+	}
+	t.closed = true
+	for i := range t.events {
+		if t.events[i].Ph == "X" {
+			t.events[i].Ts = t.events[i].start.Sub(t.start).Microseconds()
+		}
+	}
+	err = json.NewEncoder(t.w).Encode(struct {
+		TraceEvents     []chromeTraceEvent `json:"traceEvents"`
+		DisplayTimeUnit string             `json:"displayTimeUnit"`
+	}{
+		TraceEvents:     t.events,
+		DisplayTimeUnit: "ms",
+	})
+	if t.closer != nil {
+		if closeErr := t.closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+func formatSpanID(id uint64) string {
+	return strconv.FormatUint(id, 10)
+}
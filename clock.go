@@ -0,0 +1,101 @@
+package supervisor
+
+import (
+	"context"
+	"time"
+)
+
+// Clock provides time to time-driven components. Use WithClock to provide
+// custom Clock to components.
+type Clock interface {
+
+	// Now returns current time
+	Now() time.Time
+
+	// After waits for the duration to elapse and then sends the current time
+	// on the returned channel.
+	After(d time.Duration) <-chan time.Time
+
+	// NewTimer creates new Timer
+	NewTimer(d time.Duration) Timer
+
+	// NewTicker creates new Ticker
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer is time.Timer abstraction
+type Timer interface {
+
+	// C returns channel on which the time is delivered
+	C() <-chan time.Time
+
+	// Stop prevents the Timer from firing
+	Stop() (ok bool)
+
+	// Reset changes the timer to expire after given duration
+	Reset(d time.Duration) (ok bool)
+}
+
+// Ticker is time.Ticker abstraction
+type Ticker interface {
+
+	// C returns channel on which the ticks are delivered
+	C() <-chan time.Time
+
+	// Stop turns off Ticker
+	Stop()
+}
+
+// RealClock is Clock backed by time package
+var RealClock Clock = realClock{}
+
+type clockKey struct{}
+
+// WithClock returns copy of Context with given Clock. All time-driven
+// components created with returned Context will use given Clock.
+func WithClock(ctx context.Context, clock Clock) context.Context {
+	return context.WithValue(ctx, clockKey{}, clock)
+}
+
+// ClockFrom returns Clock from given Context. If Context has no Clock
+// ClockFrom returns RealClock.
+func ClockFrom(ctx context.Context) (clock Clock) {
+	if clock, ok := ctx.Value(clockKey{}).(Clock); ok {
+		return clock
+	}
+	return RealClock
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+type realTimer struct {
+	*time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
@@ -1,5 +1,10 @@
 package supervisor
 
+import (
+	"context"
+	"strconv"
+)
+
 // Component is basic building block to build supervisor trees
 type Component interface {
 
@@ -13,3 +18,59 @@ type Component interface {
 	// Wait should blocks until Component shutdown.
 	Wait() (err error)
 }
+
+// Named is implemented by Components which have human-readable name.
+// Supervisors use names to identify supervised components in reports.
+type Named interface {
+
+	// Name returns Component name
+	Name() string
+}
+
+// Wrapper is implemented by Components which wrap other Component.
+type Wrapper interface {
+
+	// Unwrap returns wrapped Component
+	Unwrap() Component
+}
+
+// interrupter is implemented by Components which Open() may be interrupted
+// by closing supervisor
+type interrupter interface {
+	interruptOpen()
+}
+
+// openComponent opens given Component. Open() of Component which implements
+// interrupter or wraps one is interrupted when given Context is done.
+func openComponent(ctx context.Context, component Component) (err error) {
+	stop := context.AfterFunc(ctx, func() {
+		interruptOpen(component)
+	})
+	defer stop()
+	return component.Open()
+}
+
+// interruptOpen interrupts Open() of first interrupter starting from given
+// Component
+func interruptOpen(component Component) {
+	for component != nil {
+		if i, ok := component.(interrupter); ok {
+			i.interruptOpen()
+			return
+		}
+		wrapper, ok := component.(Wrapper)
+		if !ok {
+			return
+		}
+		component = wrapper.Unwrap()
+	}
+}
+
+// nameOf returns name of supervised component. If component and all
+// components wrapped by it are not Named its position in supervisor is used.
+func nameOf(component Component, index int) (name string) {
+	if name = NameOf(component); name != "" {
+		return name
+	}
+	return strconv.Itoa(index)
+}
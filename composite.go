@@ -2,43 +2,95 @@ package supervisor
 
 import (
 	"context"
+	"github.com/akaspin/errslice"
 	"sync"
-	"sync/atomic"
+	"time"
 )
 
 type compositeControl struct {
+	id         string // supervisor ID
+	kind       string // supervisor kind
+	clock      Clock
+	log        eventLogger
+	trace      *tracer
 	ctx        context.Context
 	cancelFunc context.CancelFunc
 
-	open uint32
+	openChan chan struct{} // closed after open or premature close
 
-	closeWg sync.WaitGroup
-	waitWg  sync.WaitGroup // WG to wait for exit of all components
+	closeMu    sync.Mutex
+	closeChans []chan struct{} // closed after Close() of opened components
+	waitWg     sync.WaitGroup  // WG to wait for exit of all components
 
 	openError  compositeError
 	closeError compositeError
 	waitError  compositeError // composite Wait() error
+
+	closeOnce   sync.Once
+	closeResult error // result of first Close()
+
+	report shutdownRecorder
+}
+
+// do calls given function with goroutine labels of supervised component
+// with given name. All goroutines started by function inherit labels.
+func (c *compositeControl) do(name string, fn func()) {
+	withLabels(c.ctx, c.id, name, fn)
 }
 
-func (c *compositeControl) isOpen() (ok bool) {
-	return atomic.LoadUint32(&c.open) == 1
+// addCloser registers Close() of opened component. Returned function
+// should be called after Close() is returned.
+func (c *compositeControl) addCloser() (done func()) {
+	closeChan := make(chan struct{})
+	c.closeMu.Lock()
+	c.closeChans = append(c.closeChans, closeChan)
+	c.closeMu.Unlock()
+	return func() {
+		close(closeChan)
+	}
+}
+
+// waitClosers waits for Close() of all registered components
+func (c *compositeControl) waitClosers() {
+	c.closeMu.Lock()
+	closeChans := c.closeChans
+	c.closeMu.Unlock()
+	for _, closeChan := range closeChans {
+		<-closeChan
+	}
 }
 
-func (c *compositeControl) tryOpen() (op bool) {
-	return atomic.CompareAndSwapUint32(&c.open, 0, 1)
+// since returns time elapsed since given time
+func (c *compositeControl) since(t time.Time) time.Duration {
+	return c.clock.Now().Sub(t)
 }
 
 type composite struct {
+	*lifecycle
+	name    string
 	handler func(control *compositeControl)
 	control *compositeControl
 }
 
-func newComposite(ctx context.Context, handler func(control *compositeControl)) (c *composite) {
+func newComposite(ctx context.Context, kind string, handler func(control *compositeControl)) (c *composite) {
 	c = &composite{
-		handler: handler,
-		control: &compositeControl{},
+		lifecycle: newLifecycle(ClockFrom(ctx)),
+		name:      baseName(PathFrom(ctx)),
+		handler:   handler,
+		control: &compositeControl{
+			id:       newSupervisorID(kind),
+			kind:     kind,
+			clock:    ClockFrom(ctx),
+			log:      newEventLogger(ctx),
+			trace:    newTracer(ctx),
+			openChan: make(chan struct{}),
+			report: shutdownRecorder{
+				clock: ClockFrom(ctx),
+			},
+		},
 	}
 	c.control.ctx, c.control.cancelFunc = context.WithCancel(ctx)
+	context.AfterFunc(c.control.ctx, c.shutdown)
 	return c
 }
 
@@ -48,36 +100,35 @@ func newComposite(ctx context.Context, handler func(control *compositeControl))
 // method may be called many times and will return equal results. It's
 // guaranteed that Open() method of all components will be called only once.
 func (c *composite) Open() (err error) {
-	select {
-	case <-c.control.ctx.Done(): // closed
-		if !c.control.isOpen() {
-			// closed before open
+	if c.control.ctx.Err() != nil || !c.transit(StateOpening, nil, StateNew) {
+		<-c.control.openChan
+		if c.isPrematurelyClosed() {
 			return ErrPrematurelyClosed
 		}
 		return c.control.openError.get()
-	default:
 	}
-	if !c.control.tryOpen() {
-		return c.control.openError.get()
-	}
-
 	c.handler(c.control)
+	c.transit(StateOpen, nil, StateOpening)
+	close(c.control.openChan)
 	return c.control.openError.get()
 }
 
 // Close initialises shutdown for all Components. This method may be called
 // many times and will return equal results. It's guaranteed that Close()
-// method of all components will be called only once.
+// method of all components will be called only once. Close does not wait
+// for concurrent Open() and returns errors only of components opened before
+// first Close() call.
 func (c *composite) Close() (err error) {
-	select {
-	case <-c.control.ctx.Done():
-		// already closed
-		return c.control.closeError.get()
-	default:
-		c.control.cancelFunc()
-		c.control.closeWg.Wait()
-	}
-	return c.control.closeError.get()
+	c.control.closeOnce.Do(func() {
+		if c.control.ctx.Err() == nil {
+			c.control.report.initiate("", "", nil)
+			c.closeNew()
+			c.control.cancelFunc()
+		}
+		c.control.waitClosers()
+		c.control.closeResult = c.control.closeError.get()
+	})
+	return c.control.closeResult
 }
 
 // Wait blocks until all components are exited. If one of Wait() method of one
@@ -85,7 +136,52 @@ func (c *composite) Close() (err error) {
 // This method may be called many times and will return equal results. It's
 // guaranteed that Wait() method of all components will be called only once.
 func (c *composite) Wait() (err error) {
-	<-c.control.ctx.Done()
-	c.control.waitWg.Wait()
+	<-c.doneChan()
 	return c.control.waitError.get()
 }
+
+// Name returns name from Context provided on construction. See WithName.
+func (c *composite) Name() string {
+	return c.name
+}
+
+// ShutdownReport returns report of shutdown. Report contains Component
+// which initiated shutdown and timeline of Close() and Wait() completions of
+// all supervised components.
+func (c *composite) ShutdownReport() (report ShutdownReport) {
+	return c.control.report.get()
+}
+
+// shutdown is called then composite context is done
+func (c *composite) shutdown() {
+	if c.closeNew() || c.isPrematurelyClosed() {
+		return
+	}
+	c.closing()
+	c.control.do("", func() {
+		<-c.control.openChan
+		c.control.waitWg.Wait()
+	})
+	c.exit(errslice.Append(c.control.openError.get(), c.control.waitError.get()))
+}
+
+// closeNew closes composite which is not opened
+func (c *composite) closeNew() (ok bool) {
+	if c.transit(StateClosed, nil, StateNew) {
+		close(c.control.openChan)
+		return true
+	}
+	return false
+}
+
+func (c *composite) supervisorID() string {
+	return c.control.id
+}
+
+func (c *composite) supervisorKind() string {
+	return c.control.kind
+}
+
+func (c *composite) spans() *tracer {
+	return c.control.trace
+}
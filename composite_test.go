@@ -76,6 +76,10 @@ func (c *testingComponent) assertCycle(t *testing.T) {
 	c.assertEvents(t, "open", "close", "done")
 }
 
+func (c *testingComponent) Name() string {
+	return c.name
+}
+
 func (c *testingComponent) Open() (err error) {
 	err = c.errOpen
 	c.appendEvent("open")
@@ -98,3 +102,16 @@ func (c *testingComponent) Wait() (err error) {
 	//println("w", c.name)
 	return
 }
+
+// gatedComponent blocks Open() until gate is closed
+type gatedComponent struct {
+	*testingComponent
+	opening chan struct{} // closed then Open() is called
+	gate    chan struct{}
+}
+
+func (c *gatedComponent) Open() (err error) {
+	close(c.opening)
+	<-c.gate
+	return c.testingComponent.Open()
+}
@@ -0,0 +1,55 @@
+package supervisor_test
+
+import (
+	"context"
+	"github.com/akaspin/supervisor"
+	"github.com/akaspin/supervisor/supervisortest"
+	"testing"
+	"time"
+)
+
+func TestConformance(t *testing.T) {
+	t.Run("control", func(t *testing.T) {
+		supervisortest.Conformance(t, func() supervisor.Component {
+			return supervisor.NewControl(context.Background())
+		})
+	})
+	t.Run("trap", func(t *testing.T) {
+		supervisortest.Conformance(t, func() supervisor.Component {
+			return supervisor.NewTrap(context.Background())
+		})
+	})
+	t.Run("timeout", func(t *testing.T) {
+		supervisortest.Conformance(t, func() supervisor.Component {
+			return supervisor.NewTimeout(context.Background(), time.Second, supervisor.NewControl(context.Background()))
+		})
+	})
+	t.Run("chain", func(t *testing.T) {
+		supervisortest.Conformance(t, func() supervisor.Component {
+			return supervisor.NewChain(context.Background(),
+				supervisor.NewControl(context.Background()),
+				supervisor.NewTrap(context.Background()),
+			)
+		})
+	})
+	t.Run("group", func(t *testing.T) {
+		supervisortest.Conformance(t, func() supervisor.Component {
+			return supervisor.NewGroup(context.Background(),
+				supervisor.NewControl(context.Background()),
+				supervisor.NewTrap(context.Background()),
+			)
+		})
+	})
+	t.Run("nested", func(t *testing.T) {
+		supervisortest.Conformance(t, func() supervisor.Component {
+			return supervisor.NewChain(context.Background(),
+				supervisor.NewGroup(context.Background(),
+					supervisor.NewControl(context.Background()),
+				),
+				supervisor.NewTimeout(context.Background(), time.Second,
+					supervisor.NewTrap(context.Background()),
+				),
+			)
+		})
+	})
+}
@@ -2,50 +2,209 @@ package supervisor
 
 import (
 	"context"
-	"sync/atomic"
+	"github.com/akaspin/errslice"
+	"log/slog"
+	"sync"
 )
 
 // Control is simplest embeddable component
 type Control struct {
+	*lifecycle
+	name   string
 	ctx    context.Context
 	cancel context.CancelFunc
 
-	isOpen uint32
+	openOnce sync.Once
+	openErr  error // result of openWith()
+
+	loggerMu sync.Mutex
+	logger   *slog.Logger // child Logger injected by supervisor
+
+	goMu       sync.Mutex
+	goWg       sync.WaitGroup // tracked goroutines
+	goStopping bool           // set then shutdown started to wait goroutines
+	failErr    compositeError // errors from Fail() and tracked goroutines
+
+	hooksMu   sync.Mutex
+	hooks     []func() error // OnClose() hooks
+	hooksDone bool
+	closeErr  compositeError // errors from OnClose() hooks
 }
 
-// NewControl returns new Control
+// NewControl returns new Control. Control uses name from given Context. See
+// WithName.
 func NewControl(ctx context.Context) (c *Control) {
-	c = &Control{}
-	c.ctx, c.cancel = context.WithCancel(ctx)
+	c = &Control{
+		lifecycle: newLifecycle(ClockFrom(ctx)),
+		name:      baseName(PathFrom(ctx)),
+	}
+	ctx, c.cancel = context.WithCancel(ctx)
+	c.ctx = controlContext{Context: ctx, control: c}
+	context.AfterFunc(c.ctx, c.shutdown)
 	return
 }
 
-// Open sets Control in open state
+// Name returns name from Context provided on construction. See WithName.
+func (c *Control) Name() string {
+	return c.name
+}
+
+// Open sets Control in open state. Open returns ErrPrematurelyClosed if
+// Control is closed before open.
 func (c *Control) Open() (err error) {
-	atomic.CompareAndSwapUint32(&c.isOpen, 0, 1)
-	return nil
+	return c.lifecycle.open(c.ctx)
 }
 
-// Close closes Control context
+// openWith opens Control and calls given function once. Repeated and
+// concurrent calls return the result of the first call. Components which
+// embed Control use openWith to acquire resources in Open() only once.
+func (c *Control) openWith(fn func() (err error)) (err error) {
+	c.openOnce.Do(func() {
+		if c.openErr = c.Open(); c.openErr == nil {
+			c.openErr = fn()
+		}
+	})
+	return c.openErr
+}
+
+// Close closes Control context. Close of opened Control does not wait for
+// goroutines started by Go() and OnClose() hooks. Use Wait() to get their
+// errors.
 func (c *Control) Close() (err error) {
 	c.cancel()
+	if !c.isOpened() {
+		<-c.doneChan()
+	}
 	return nil
 }
 
-// Wait blocks until internal done context is not done. See Done().
+// Wait blocks until Control context is done and all goroutines started by
+// Go() are returned. Wait returns errors passed to Fail(), errors returned
+// by goroutines and errors of OnClose() hooks.
 func (c *Control) Wait() (err error) {
-	<-c.ctx.Done()
-	return nil
+	<-c.doneChan()
+	return c.err()
+}
+
+// Fail closes Control with given error. Error will be returned by Wait().
+// Nil errors are ignored.
+func (c *Control) Fail(err error) {
+	if err == nil {
+		return
+	}
+	c.failErr.set(err)
+	c.cancel()
+}
+
+// OnClose adds hook which will be called on close after all goroutines
+// started by Go() are returned. Hooks are called in LIFO order. Use OnClose
+// to release resources acquired in Open(). If all hooks are already called
+// hook will be called immediately and its error will be logged.
+func (c *Control) OnClose(fn func() (err error)) {
+	c.hooksMu.Lock()
+	if !c.hooksDone {
+		c.hooks = append(c.hooks, fn)
+		c.hooksMu.Unlock()
+		return
+	}
+	c.hooksMu.Unlock()
+	if err := fn(); err != nil {
+		LoggerFrom(c.Ctx()).LogAttrs(c.Ctx(), slog.LevelError, "close hook failed",
+			slog.String(LogError, err.Error()),
+		)
+	}
 }
 
-// Ctx returns Control context
+// runHooks runs OnClose() hooks including hooks added by hooks
+func (c *Control) runHooks() {
+	for {
+		c.hooksMu.Lock()
+		if len(c.hooks) == 0 {
+			c.hooksDone = true
+			c.hooksMu.Unlock()
+			return
+		}
+		fn := c.hooks[len(c.hooks)-1]
+		c.hooks = c.hooks[:len(c.hooks)-1]
+		c.hooksMu.Unlock()
+		if err := fn(); err != nil {
+			c.closeErr.set(err)
+		}
+	}
+}
+
+// shutdown is called then Control context is done
+func (c *Control) shutdown() {
+	c.closing()
+	c.goMu.Lock()
+	c.goStopping = true
+	c.goMu.Unlock()
+	c.goWg.Wait()
+	c.runHooks()
+	c.exit(c.err())
+}
+
+// err returns errors passed to Fail() and errors of OnClose() hooks
+func (c *Control) err() (err error) {
+	return errslice.Append(c.failErr.get(), c.closeErr.get())
+}
+
+// Go runs given function in tracked goroutine with Control context. If
+// function returns error Control will be closed like with Fail(). Go does
+// nothing if Control is already closing.
+func (c *Control) Go(fn func(ctx context.Context) (err error)) {
+	c.goMu.Lock()
+	defer c.goMu.Unlock()
+	if c.goStopping {
+		return
+	}
+	c.goWg.Add(1)
+	go func() {
+		defer c.goWg.Done()
+		c.Fail(fn(c.ctx))
+	}()
+}
+
+// Ctx returns Control context. Context of supervised Control carries child
+// Logger. See LoggerFrom.
 func (c *Control) Ctx() context.Context {
 	return c.ctx
 }
 
+// injectLogger sets child Logger returned by LoggerFrom for Control context
+// and all contexts derived from it
+func (c *Control) injectLogger(logger *slog.Logger) {
+	c.loggerMu.Lock()
+	c.logger = logger
+	c.loggerMu.Unlock()
+}
+
+// componentLogger returns injected child Logger or nil
+func (c *Control) componentLogger() (logger *slog.Logger) {
+	c.loggerMu.Lock()
+	defer c.loggerMu.Unlock()
+	return c.logger
+}
+
+// controlContext is Control context which carries child Logger injected
+// after Control is constructed
+type controlContext struct {
+	context.Context
+	control *Control
+}
+
+func (ctx controlContext) Value(key any) any {
+	if _, ok := key.(componentLoggerKey); ok {
+		if logger := ctx.control.componentLogger(); logger != nil {
+			return logger
+		}
+	}
+	return ctx.Context.Value(key)
+}
+
 // IsOpen returns true if Control is opened
 func (c *Control) IsOpen() (ok bool) {
-	return atomic.LoadUint32(&c.isOpen) == 1
+	return c.isOpened()
 }
 
 // IsClosed returns true if control is closed
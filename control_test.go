@@ -0,0 +1,166 @@
+package supervisor_test
+
+import (
+	"context"
+	"errors"
+	"github.com/akaspin/supervisor"
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestControl_Go(t *testing.T) {
+	for i := 0; i < compositeTestIterations; i++ {
+		t.Run("wait goroutines "+strconv.Itoa(i), func(t *testing.T) {
+			t.Parallel()
+			var exited uint32
+			c := supervisor.NewControl(context.Background())
+			assert.NoError(t, c.Open())
+			for j := 0; j < 3; j++ {
+				c.Go(func(ctx context.Context) (err error) {
+					<-ctx.Done()
+					atomic.AddUint32(&exited, 1)
+					return nil
+				})
+			}
+			assert.NoError(t, c.Close())
+			assert.NoError(t, c.Wait())
+			assert.Equal(t, uint32(3), atomic.LoadUint32(&exited))
+			assert.NoError(t, c.Wait())
+		})
+		t.Run("error "+strconv.Itoa(i), func(t *testing.T) {
+			t.Parallel()
+			c := supervisor.NewControl(context.Background())
+			assert.NoError(t, c.Open())
+			c.Go(func(ctx context.Context) (err error) {
+				<-ctx.Done()
+				return errors.New("1")
+			})
+			c.Go(func(context.Context) (err error) {
+				return errors.New("2")
+			})
+			assert.EqualError(t, c.Wait(), "2,1")
+			assert.True(t, c.IsClosed())
+			assert.EqualError(t, c.Wait(), "2,1")
+		})
+		t.Run("concurrent close "+strconv.Itoa(i), func(t *testing.T) {
+			t.Parallel()
+			c := supervisor.NewControl(context.Background())
+			assert.NoError(t, c.Open())
+			go c.Close()
+			for j := 0; j < 10; j++ {
+				c.Go(func(ctx context.Context) (err error) {
+					<-ctx.Done()
+					return nil
+				})
+			}
+			assert.NoError(t, c.Wait())
+			c.Go(func(context.Context) (err error) {
+				return errors.New("late")
+			})
+			assert.NoError(t, c.Wait())
+		})
+	}
+}
+
+func TestControl_Fail(t *testing.T) {
+	c := supervisor.NewControl(context.Background())
+	assert.NoError(t, c.Open())
+	c.Fail(nil)
+	assert.False(t, c.IsClosed())
+	c.Fail(errors.New("1"))
+	c.Fail(errors.New("2"))
+	assert.True(t, c.IsClosed())
+	assert.EqualError(t, c.Wait(), "1,2")
+	assert.NoError(t, c.Close())
+}
+
+func TestControl_OnClose(t *testing.T) {
+	t.Run("close", func(t *testing.T) {
+		var res []string
+		c := supervisor.NewControl(context.Background())
+		assert.NoError(t, c.Open())
+		for _, name := range []string{"1", "2", "3"} {
+			name := name
+			c.OnClose(func() (err error) {
+				res = append(res, name)
+				if name != "2" {
+					return errors.New(name)
+				}
+				return nil
+			})
+		}
+		assert.NoError(t, c.Close())
+		assert.EqualError(t, c.Wait(), "3,1")
+		assert.NoError(t, c.Close())
+		assert.Equal(t, []string{"3", "2", "1"}, res)
+
+		c.OnClose(func() (err error) {
+			res = append(res, "4")
+			return errors.New("4")
+		})
+		assert.Equal(t, []string{"3", "2", "1", "4"}, res)
+		assert.EqualError(t, c.Wait(), "3,1")
+	})
+	t.Run("fail", func(t *testing.T) {
+		var res []string
+		c := supervisor.NewControl(context.Background())
+		assert.NoError(t, c.Open())
+		c.OnClose(func() (err error) {
+			res = append(res, "1")
+			return errors.New("1")
+		})
+		c.Fail(errors.New("fail"))
+		assert.EqualError(t, c.Wait(), "fail,1")
+		assert.NoError(t, c.Close())
+		assert.Equal(t, []string{"1"}, res)
+	})
+	t.Run("after goroutines", func(t *testing.T) {
+		var exited uint32
+		c := supervisor.NewControl(context.Background())
+		assert.NoError(t, c.Open())
+		c.Go(func(ctx context.Context) (err error) {
+			<-ctx.Done()
+			time.Sleep(time.Millisecond * 10)
+			atomic.StoreUint32(&exited, 1)
+			return nil
+		})
+		c.OnClose(func() (err error) {
+			if atomic.LoadUint32(&exited) != 1 {
+				return errors.New("goroutine is running")
+			}
+			return nil
+		})
+		assert.NoError(t, c.Close())
+		assert.NoError(t, c.Wait())
+	})
+	t.Run("close from goroutine", func(t *testing.T) {
+		c := supervisor.NewControl(context.Background())
+		assert.NoError(t, c.Open())
+		c.OnClose(func() (err error) {
+			return errors.New("1")
+		})
+		c.Go(func(ctx context.Context) (err error) {
+			return c.Close()
+		})
+		assert.EqualError(t, c.Wait(), "1")
+	})
+	t.Run("reentrant", func(t *testing.T) {
+		var res []string
+		c := supervisor.NewControl(context.Background())
+		assert.NoError(t, c.Open())
+		c.OnClose(func() (err error) {
+			c.OnClose(func() (err error) {
+				res = append(res, "2")
+				return nil
+			})
+			res = append(res, "1")
+			return c.Close()
+		})
+		assert.NoError(t, c.Close())
+		assert.NoError(t, c.Wait())
+		assert.Equal(t, []string{"1", "2"}, res)
+	})
+}
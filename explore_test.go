@@ -0,0 +1,50 @@
+package supervisor_test
+
+import (
+	"context"
+	"github.com/akaspin/supervisor"
+	"github.com/akaspin/supervisor/supervisortest"
+	"testing"
+	"time"
+)
+
+func TestExplore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping exploration in short mode")
+	}
+	explorer := supervisortest.Explorer{
+		Seeds: 30,
+	}
+	t.Run("chain", func(t *testing.T) {
+		t.Parallel()
+		explorer.Run(t, func(s *supervisortest.Scheduler) supervisor.Component {
+			return supervisor.NewChain(context.Background(),
+				s.Component("1"),
+				s.Component("2").Fallible(),
+				s.Component("3"),
+			)
+		})
+	})
+	t.Run("group", func(t *testing.T) {
+		t.Parallel()
+		explorer.Run(t, func(s *supervisortest.Scheduler) supervisor.Component {
+			return supervisor.NewGroup(context.Background(),
+				s.Component("1"),
+				s.Component("2").Fallible(),
+				s.Component("3"),
+			)
+		})
+	})
+	t.Run("nested", func(t *testing.T) {
+		t.Parallel()
+		explorer.Run(t, func(s *supervisortest.Scheduler) supervisor.Component {
+			return supervisor.NewChain(context.Background(),
+				s.Component("1"),
+				supervisor.NewGroup(context.Background(),
+					s.Component("2"),
+					supervisor.NewTimeout(context.Background(), time.Minute, s.Component("3").Fallible()),
+				),
+			)
+		})
+	})
+}
@@ -1,6 +1,13 @@
 module github.com/akaspin/supervisor
 
+go 1.24
+
 require (
 	github.com/akaspin/errslice v1.0.1
 	github.com/stretchr/testify v1.2.2
 )
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+)
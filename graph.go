@@ -0,0 +1,203 @@
+package supervisor
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// GraphOptions configures diagrams written by WriteDOT and WriteMermaid
+type GraphOptions struct {
+
+	// States colours Stateful components by their current State
+	States bool
+}
+
+var stateColors = [...]string{
+	StateNew:     "#ffffff",
+	StateOpening: "#fff3b0",
+	StateOpen:    "#b7e4c7",
+	StateClosing: "#ffd6a5",
+	StateClosed:  "#dddddd",
+	StateFailed:  "#ffadad",
+}
+
+// graphNode is node of supervisor tree
+type graphNode struct {
+	id       string
+	name     string
+	kind     string // supervisor kind or empty for custom components
+	state    State
+	stateful bool
+	children []*graphNode
+}
+
+// newGraph walks supervisor tree with given root
+func newGraph(root Component) (node *graphNode) {
+	last := 0
+	var walk func(component Component, name string) *graphNode
+	walk = func(component Component, name string) *graphNode {
+		last++
+		node := &graphNode{
+			id:   "n" + strconv.Itoa(last),
+			name: name,
+		}
+		for c := component; c != nil; {
+			if stateful, ok := c.(Stateful); ok && !node.stateful {
+				node.stateful = true
+				node.state = stateful.State()
+			}
+			if sv, ok := c.(supervisor); ok {
+				node.kind = sv.supervisorKind()
+				for index, child := range sv.children() {
+					node.children = append(node.children, walk(child, nameOf(child, index)))
+				}
+				break
+			}
+			wrapper, ok := c.(Wrapper)
+			if !ok {
+				break
+			}
+			c = wrapper.Unwrap()
+		}
+		if node.name == "" {
+			node.name = node.kind
+		}
+		return node
+	}
+	return walk(root, NameOf(root))
+}
+
+// label returns node label
+func (n *graphNode) label() string {
+	if n.kind == "" || n.kind == n.name {
+		return n.name
+	}
+	return n.name + " (" + n.kind + ")"
+}
+
+// first returns ID of node which represents given node in edges
+func (n *graphNode) first() string {
+	if n.kind == "" {
+		return n.id
+	}
+	return n.id + "_anchor"
+}
+
+func (n *graphNode) color(options GraphOptions) (color string) {
+	if options.States && n.stateful && int(n.state) < len(stateColors) {
+		return stateColors[n.state]
+	}
+	return ""
+}
+
+/*
+WriteDOT writes supervisor tree with given root as Graphviz DOT digraph.
+Supervisors are rendered as clusters. Components in Chain are connected by
+edges in open order. Components in Group are not connected because they are
+opened in parallel.
+*/
+func WriteDOT(w io.Writer, root Component, options GraphOptions) (err error) {
+	b := bufio.NewWriter(w)
+	b.WriteString("digraph supervisor {\n")
+	b.WriteString("\tcompound=true;\n")
+	b.WriteString("\tnode [shape=box, style=\"rounded,filled\", fillcolor=\"#ffffff\"];\n")
+	writeDOTNode(b, newGraph(root), options, "\t")
+	b.WriteString("}\n")
+	return b.Flush()
+}
+
+func writeDOTNode(b *bufio.Writer, node *graphNode, options GraphOptions, indent string) {
+	color := node.color(options)
+	if node.kind == "" {
+		b.WriteString(indent + node.id + " [label=" + strconv.Quote(node.label()))
+		if color != "" {
+			b.WriteString(", fillcolor=" + strconv.Quote(color))
+		}
+		b.WriteString("];\n")
+		return
+	}
+	b.WriteString(indent + "subgraph cluster_" + node.id + " {\n")
+	inner := indent + "\t"
+	b.WriteString(inner + "label=" + strconv.Quote(node.label()) + ";\n")
+	if color != "" {
+		b.WriteString(inner + "style=filled;\n")
+		b.WriteString(inner + "fillcolor=" + strconv.Quote(color) + ";\n")
+	}
+	b.WriteString(inner + node.first() + " [shape=point, style=invis];\n")
+	for _, child := range node.children {
+		writeDOTNode(b, child, options, inner)
+	}
+	if node.kind == "chain" {
+		for i := 1; i < len(node.children); i++ {
+			from, to := node.children[i-1], node.children[i]
+			b.WriteString(inner + from.first() + " -> " + to.first())
+			var attrs []string
+			if from.kind != "" {
+				attrs = append(attrs, "ltail=cluster_"+from.id)
+			}
+			if to.kind != "" {
+				attrs = append(attrs, "lhead=cluster_"+to.id)
+			}
+			if len(attrs) > 0 {
+				b.WriteString(" [" + strings.Join(attrs, ", ") + "]")
+			}
+			b.WriteString(";\n")
+		}
+	}
+	b.WriteString(indent + "}\n")
+}
+
+/*
+WriteMermaid writes supervisor tree with given root as Mermaid flowchart.
+Supervisors are rendered as subgraphs. Components in Chain are connected by
+edges in open order. Components in Group are not connected because they are
+opened in parallel.
+*/
+func WriteMermaid(w io.Writer, root Component, options GraphOptions) (err error) {
+	b := bufio.NewWriter(w)
+	b.WriteString("flowchart TB\n")
+	graph := newGraph(root)
+	writeMermaidNode(b, graph, "\t")
+	if options.States {
+		for state, color := range stateColors {
+			b.WriteString("\tclassDef " + State(state).String() + " fill:" + color + ";\n")
+		}
+		writeMermaidStates(b, graph)
+	}
+	return b.Flush()
+}
+
+func writeMermaidNode(b *bufio.Writer, node *graphNode, indent string) {
+	label := "[\"" + strings.Replace(node.label(), "\"", "#quot;", -1) + "\"]"
+	if node.kind == "" {
+		b.WriteString(indent + node.id + label + "\n")
+		return
+	}
+	b.WriteString(indent + "subgraph " + node.id + label + "\n")
+	inner := indent + "\t"
+	if node.kind == "group" {
+		b.WriteString(inner + "direction LR\n")
+	} else {
+		b.WriteString(inner + "direction TB\n")
+	}
+	for _, child := range node.children {
+		writeMermaidNode(b, child, inner)
+	}
+	if node.kind == "chain" {
+		for i := 1; i < len(node.children); i++ {
+			b.WriteString(inner + node.children[i-1].id + " --> " + node.children[i].id + "\n")
+		}
+	}
+	b.WriteString(indent + "end\n")
+}
+
+func writeMermaidStates(b *bufio.Writer, node *graphNode) {
+	if node.stateful {
+		b.WriteString("\tclass " + node.id + " " + node.state.String() + ";\n")
+	}
+	for _, child := range node.children {
+		writeMermaidStates(b, child)
+	}
+}
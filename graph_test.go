@@ -0,0 +1,87 @@
+package supervisor_test
+
+import (
+	"bytes"
+	"context"
+	"github.com/akaspin/supervisor"
+	"github.com/akaspin/supervisor/supervisortest"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func newGraphTree(ctx context.Context) supervisor.Component {
+	return supervisor.NewChain(supervisor.WithName(ctx, "app"),
+		supervisortest.NewComponent("db", nil),
+		supervisor.NewGroup(supervisor.WithName(ctx, "api"),
+			supervisor.NewTimeout(ctx, time.Second, supervisortest.NewComponent("http", nil)),
+			supervisor.NewControl(supervisor.WithName(ctx, "grpc")),
+		),
+	)
+}
+
+func TestWriteDOT(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, supervisor.WriteDOT(&buf, newGraphTree(context.Background()), supervisor.GraphOptions{
+		States: true,
+	}))
+	assert.Equal(t, `digraph supervisor {
+	compound=true;
+	node [shape=box, style="rounded,filled", fillcolor="#ffffff"];
+	subgraph cluster_n1 {
+		label="app (chain)";
+		style=filled;
+		fillcolor="#ffffff";
+		n1_anchor [shape=point, style=invis];
+		n2 [label="db"];
+		subgraph cluster_n3 {
+			label="api (group)";
+			style=filled;
+			fillcolor="#ffffff";
+			n3_anchor [shape=point, style=invis];
+			subgraph cluster_n4 {
+				label="http (timeout)";
+				style=filled;
+				fillcolor="#ffffff";
+				n4_anchor [shape=point, style=invis];
+				n5 [label="http"];
+			}
+			n6 [label="grpc", fillcolor="#ffffff"];
+		}
+		n2 -> n3_anchor [lhead=cluster_n3];
+	}
+}
+`, buf.String())
+}
+
+func TestWriteMermaid(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, supervisor.WriteMermaid(&buf, newGraphTree(context.Background()), supervisor.GraphOptions{}))
+	assert.Equal(t, `flowchart TB
+	subgraph n1["app (chain)"]
+		direction TB
+		n2["db"]
+		subgraph n3["api (group)"]
+			direction LR
+			subgraph n4["http (timeout)"]
+				direction TB
+				n5["http"]
+			end
+			n6["grpc"]
+		end
+		n2 --> n3
+	end
+`, buf.String())
+
+	t.Run("states", func(t *testing.T) {
+		var buf bytes.Buffer
+		sv := supervisor.NewGroup(context.Background(), supervisor.NewControl(context.Background()))
+		assert.NoError(t, sv.Open())
+		assert.NoError(t, supervisor.WriteMermaid(&buf, sv, supervisor.GraphOptions{States: true}))
+		assert.Contains(t, buf.String(), "\tn2[\"0\"]\n")
+		assert.Contains(t, buf.String(), "\tclassDef open fill:#b7e4c7;\n")
+		assert.Contains(t, buf.String(), "\tclass n1 open;\n\tclass n2 open;\n")
+		assert.NoError(t, sv.Close())
+		assert.NoError(t, sv.Wait())
+	})
+}
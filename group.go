@@ -20,48 +20,91 @@ type Group struct {
 
 // NewGroup creates new Group. Provided context manages whole Group. Close
 // Context is equivalent to call Group.Close().
+// Middlewares from Context are applied to all components. See WithMiddleware.
+// Health of components which implement Checker is checked. See
+// WithHealthCheck.
 func NewGroup(ctx context.Context, components ...Component) (g *Group) {
 	g = &Group{
-		components: components,
+		components: applyMiddlewares(ctx, applyHealthChecks(ctx, injectLoggers(ctx, components))),
 	}
-	g.composite = newComposite(ctx, g.build)
+	g.composite = newComposite(ctx, "group", g.build)
 	return g
 }
 
 func (g *Group) build(control *compositeControl) {
 	var wg sync.WaitGroup
 	wg.Add(len(g.components))
-	for _, component := range g.components {
-		go func(component Component) {
+	for index, component := range g.components {
+		go func(index int, component Component) {
 			defer wg.Done()
-			if openErr := component.Open(); openErr != nil {
-				control.openError.set(openErr)
-				control.cancelFunc()
-				return
-			}
-			// close watchdog
-			control.closeWg.Add(1)
-			var waitExited uint32
-			go func() {
-				defer control.closeWg.Done()
-				<-control.ctx.Done()
-				if atomic.CompareAndSwapUint32(&waitExited, 0, 1) {
-					if closeErr := component.Close(); closeErr != nil {
-						control.closeError.set(closeErr)
-					}
-				}
-			}()
-			// wait watchdog
-			control.waitWg.Add(1)
-			go func() {
-				defer control.waitWg.Done()
-				if waitErr := component.Wait(); waitErr != nil {
-					control.waitError.set(waitErr)
-				}
-				atomic.CompareAndSwapUint32(&waitExited, 0, 1)
-				control.cancelFunc()
-			}()
-		}(component)
+			name := nameOf(component, index)
+			control.do(name, func() {
+				g.supervise(control, index, name, component)
+			})
+		}(index, component)
 	}
 	wg.Wait()
 }
+
+// supervise opens component and supervises its close and exit
+func (g *Group) supervise(control *compositeControl, index int, name string, component Component) {
+	trace := control.trace.child(component, name)
+	openSpan := trace.start(PhaseOpen)
+	openTime := control.clock.Now()
+	openErr := openComponent(control.ctx, component)
+	openSpan.end(openErr)
+	if openErr != nil {
+		control.log.log("fail", name, PhaseOpen, 0, openErr)
+		control.openError.set(openErr)
+		control.report.initiate(name, PhaseOpen, openErr)
+		control.cancelFunc()
+		return
+	}
+	control.log.log("open", name, PhaseOpen, control.since(openTime), nil)
+	openTime = control.clock.Now()
+	// close watchdog
+	closed := control.addCloser()
+	var waitExited uint32
+	go func() {
+		defer closed()
+		<-control.ctx.Done()
+		control.report.initiate("", "", nil)
+		if atomic.CompareAndSwapUint32(&waitExited, 0, 1) {
+			control.report.closing(index)
+			closeTime := control.clock.Now()
+			closeSpan := trace.start(PhaseClose)
+			closeErr := component.Close()
+			closeSpan.end(closeErr)
+			if closeErr != nil {
+				control.closeError.set(closeErr)
+			}
+			control.report.done(index, name, PhaseClose, closeErr)
+			control.log.log("close", name, PhaseClose, control.since(closeTime), closeErr)
+		}
+	}()
+	// wait watchdog
+	control.waitWg.Add(1)
+	waitSpan := trace.start(PhaseWait)
+	go func() {
+		defer control.waitWg.Done()
+		waitErr := component.Wait()
+		waitSpan.end(waitErr)
+		if waitErr != nil {
+			control.waitError.set(waitErr)
+		}
+		control.report.done(index, name, PhaseWait, waitErr)
+		control.log.log("exit", name, PhaseWait, control.since(openTime), waitErr)
+		atomic.CompareAndSwapUint32(&waitExited, 0, 1)
+		select {
+		case <-control.ctx.Done(): // normal shutdown
+		default:
+			control.log.log("fail", name, PhaseWait, 0, waitErr)
+			control.report.initiate(name, PhaseWait, waitErr)
+		}
+		control.cancelFunc()
+	}()
+}
+
+func (g *Group) children() (components []Component) {
+	return g.components
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"strconv"
 	"testing"
+	"time"
 )
 
 func TestGroup_Cycle(t *testing.T) {
@@ -161,3 +162,29 @@ func TestGroup_Cycle(t *testing.T) {
 	}
 
 }
+
+func TestGroup_CloseDuringOpen(t *testing.T) {
+	c1 := newTestingComponent("1", nil, errors.New("1"), nil)
+	c2 := &gatedComponent{
+		testingComponent: newTestingComponent("2", nil, errors.New("2"), nil),
+		opening:          make(chan struct{}),
+		gate:             make(chan struct{}),
+	}
+	sv := supervisor.NewGroup(context.Background(), c1, c2)
+	openChan := make(chan error, 1)
+	go func() {
+		openChan <- sv.Open()
+	}()
+	<-c2.opening
+	closeChan := make(chan error, 1)
+	go func() {
+		closeChan <- sv.Close()
+	}()
+	time.Sleep(time.Millisecond * 10)
+	close(c2.gate)
+	closeErr := <-closeChan
+	assert.NoError(t, <-openChan)
+	assert.Equal(t, closeErr, sv.Close())
+	assert.NoError(t, sv.Wait())
+	assert.Equal(t, closeErr, sv.Close())
+}
@@ -0,0 +1,218 @@
+package supervisor
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Checker is implemented by Components which can check own liveness
+type Checker interface {
+
+	// Check returns error if Component is not alive
+	Check(ctx context.Context) (err error)
+}
+
+// HealthCheckError is returned by Wait() of supervised component which
+// failed health checks
+type HealthCheckError struct {
+
+	// Component is name of supervised component
+	Component string
+
+	// Failures is number of consecutive failed checks
+	Failures int
+
+	// Err is error of last check
+	Err error
+}
+
+func (e *HealthCheckError) Error() string {
+	return "health check of " + e.Component + " failed " + strconv.Itoa(e.Failures) + " times: " + e.Err.Error()
+}
+
+// Unwrap returns error of last check
+func (e *HealthCheckError) Unwrap() error {
+	return e.Err
+}
+
+// HealthCheckConfig configures health checks of supervised components
+type HealthCheckConfig struct {
+
+	// Interval is interval between checks. Default is ten seconds.
+	Interval time.Duration
+
+	// Timeout limits each check. Default is Interval.
+	Timeout time.Duration
+
+	// Threshold is number of consecutive failed checks to consider
+	// component exited. Default is three.
+	Threshold int
+
+	// Disabled disables health checks configured by parent Context
+	Disabled bool
+}
+
+type healthCheckKey struct{}
+
+/*
+WithHealthCheck returns copy of Context with given HealthCheckConfig. Chain
+and Group created with returned Context check health of all supervised
+components which implement Checker. Without WithHealthCheck health is not
+checked. Health checks are started after successful Open() of component.
+Context passed to Check() is cancelled on Close() or exit of component.
+
+After Threshold consecutive failed checks component is closed and considered
+exited with *HealthCheckError. Supervisor handles it as any other exit of
+component before Close().
+*/
+func WithHealthCheck(ctx context.Context, config HealthCheckConfig) context.Context {
+	return context.WithValue(ctx, healthCheckKey{}, config)
+}
+
+// applyHealthChecks wraps components which implement Checker
+func applyHealthChecks(ctx context.Context, components []Component) (res []Component) {
+	config, ok := ctx.Value(healthCheckKey{}).(HealthCheckConfig)
+	if !ok || config.Disabled {
+		return components
+	}
+	if config.Interval <= 0 {
+		config.Interval = time.Second * 10
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = config.Interval
+	}
+	if config.Threshold <= 0 {
+		config.Threshold = 3
+	}
+	res = make([]Component, len(components))
+	for index, component := range components {
+		res[index] = component
+		if checker := checkerOf(component); checker != nil {
+			checked := &checkedComponent{
+				Component: component,
+				name:      nameOf(component, index),
+				checker:   checker,
+				config:    config,
+				clock:     ClockFrom(ctx),
+				failed:    make(chan struct{}),
+			}
+			checked.ctx, checked.cancel = context.WithCancel(context.Background())
+			res[index] = checked
+		}
+	}
+	return res
+}
+
+// checkerOf returns Checker implemented by given Component or any Component
+// wrapped by it
+func checkerOf(component Component) (checker Checker) {
+	for component != nil {
+		if checker, ok := component.(Checker); ok {
+			return checker
+		}
+		wrapper, ok := component.(Wrapper)
+		if !ok {
+			return nil
+		}
+		component = wrapper.Unwrap()
+	}
+	return nil
+}
+
+// checkedComponent checks health of wrapped Component
+type checkedComponent struct {
+	Component
+	name    string
+	checker Checker
+	config  HealthCheckConfig
+	clock   Clock
+
+	ctx    context.Context // cancelled on Close() or exit
+	cancel context.CancelFunc
+
+	closeOnce sync.Once
+	closeErr  error
+
+	failed  chan struct{} // closed after threshold is exceeded
+	failErr error
+}
+
+func (c *checkedComponent) Unwrap() Component {
+	return c.Component
+}
+
+func (c *checkedComponent) Open() (err error) {
+	if err = c.Component.Open(); err != nil {
+		return err
+	}
+	go c.check()
+	return nil
+}
+
+func (c *checkedComponent) Close() (err error) {
+	c.stop()
+	return c.closeComponent()
+}
+
+func (c *checkedComponent) Wait() (err error) {
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- c.Component.Wait()
+	}()
+	select {
+	case err = <-errChan:
+		c.stop()
+		return err
+	case <-c.failed:
+		c.closeComponent()
+		<-errChan
+		return c.failErr
+	}
+}
+
+// check checks health until failure or stop
+func (c *checkedComponent) check() {
+	ticker := c.clock.NewTicker(c.config.Interval)
+	defer ticker.Stop()
+	failures := 0
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C():
+		}
+		ctx, cancel := context.WithTimeout(c.ctx, c.config.Timeout)
+		err := c.checker.Check(ctx)
+		cancel()
+		if c.ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			failures = 0
+			continue
+		}
+		failures++
+		if failures >= c.config.Threshold {
+			c.failErr = &HealthCheckError{
+				Component: c.name,
+				Failures:  failures,
+				Err:       err,
+			}
+			close(c.failed)
+			return
+		}
+	}
+}
+
+func (c *checkedComponent) stop() {
+	c.cancel()
+}
+
+func (c *checkedComponent) closeComponent() (err error) {
+	c.closeOnce.Do(func() {
+		c.closeErr = c.Component.Close()
+	})
+	return c.closeErr
+}
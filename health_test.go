@@ -0,0 +1,158 @@
+package supervisor_test
+
+import (
+	"context"
+	"errors"
+	"github.com/akaspin/supervisor"
+	"github.com/akaspin/supervisor/supervisortest"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+type checkedComponent struct {
+	*supervisortest.Component
+	checks chan struct{} // receives on each check
+	mu     sync.Mutex
+	err    error
+}
+
+func newCheckedComponent(name string) *checkedComponent {
+	return &checkedComponent{
+		Component: supervisortest.NewComponent(name, nil),
+		checks:    make(chan struct{}),
+	}
+}
+
+func (c *checkedComponent) setErr(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.err = err
+}
+
+func (c *checkedComponent) Check(ctx context.Context) (err error) {
+	c.mu.Lock()
+	err = c.err
+	c.mu.Unlock()
+	c.checks <- struct{}{}
+	return err
+}
+
+// tick advances clock and waits for check
+func (c *checkedComponent) tick(clock *supervisortest.Clock, d time.Duration) {
+	clock.Advance(d)
+	<-c.checks
+}
+
+// blockingChecker blocks Check() until its Context is done
+type blockingChecker struct {
+	*supervisortest.Component
+	started   chan struct{}
+	cancelled chan struct{}
+}
+
+func (c *blockingChecker) Check(ctx context.Context) (err error) {
+	close(c.started)
+	<-ctx.Done()
+	close(c.cancelled)
+	return ctx.Err()
+}
+
+func TestWithHealthCheck(t *testing.T) {
+	t.Run("failure", func(t *testing.T) {
+		clock := supervisortest.NewClock(time.Now())
+		ctx := supervisor.WithHealthCheck(supervisor.WithClock(context.Background(), clock),
+			supervisor.HealthCheckConfig{
+				Interval:  time.Second,
+				Threshold: 2,
+			})
+		c1 := newCheckedComponent("1")
+		c2 := supervisortest.NewComponent("2", nil)
+		sv := supervisor.NewGroup(ctx, c1, c2)
+		assert.NoError(t, sv.Open())
+
+		clock.BlockUntil(1)
+		c1.tick(clock, time.Second)
+		c1.setErr(errors.New("bang"))
+		c1.tick(clock, time.Second)
+		c1.tick(clock, time.Second)
+
+		err := sv.Wait()
+		var healthErr *supervisor.HealthCheckError
+		if assert.True(t, errors.As(err, &healthErr)) {
+			assert.Equal(t, "1", healthErr.Component)
+			assert.Equal(t, 2, healthErr.Failures)
+		}
+		assert.EqualError(t, err, "health check of 1 failed 2 times: bang")
+		assert.Equal(t, "1", sv.ShutdownReport().Initiator)
+		assert.Equal(t, 1, c1.Calls(supervisor.PhaseClose))
+		assert.Equal(t, 1, c2.Calls(supervisor.PhaseClose))
+	})
+	t.Run("recovery", func(t *testing.T) {
+		clock := supervisortest.NewClock(time.Now())
+		ctx := supervisor.WithHealthCheck(supervisor.WithClock(context.Background(), clock),
+			supervisor.HealthCheckConfig{
+				Interval:  time.Second,
+				Threshold: 2,
+			})
+		c1 := newCheckedComponent("1")
+		sv := supervisor.NewChain(ctx, c1)
+		assert.NoError(t, sv.Open())
+		clock.BlockUntil(1)
+		for i := 0; i < 3; i++ {
+			c1.setErr(errors.New("bang"))
+			c1.tick(clock, time.Second)
+			c1.setErr(nil)
+			c1.tick(clock, time.Second)
+		}
+		assert.NoError(t, sv.Close())
+		assert.NoError(t, sv.Wait())
+		assert.Equal(t, 1, c1.Calls(supervisor.PhaseClose))
+	})
+	t.Run("not configured", func(t *testing.T) {
+		clock := supervisortest.NewClock(time.Now())
+		c1 := newCheckedComponent("1")
+		c1.setErr(errors.New("bang"))
+		sv := supervisor.NewGroup(supervisor.WithClock(context.Background(), clock), c1)
+		assert.NoError(t, sv.Open())
+		clock.Advance(time.Hour)
+		assert.NoError(t, sv.Close())
+		assert.NoError(t, sv.Wait())
+	})
+	t.Run("close cancels check", func(t *testing.T) {
+		clock := supervisortest.NewClock(time.Now())
+		ctx := supervisor.WithHealthCheck(supervisor.WithClock(context.Background(), clock),
+			supervisor.HealthCheckConfig{
+				Interval: time.Second,
+				Timeout:  time.Hour,
+			})
+		c1 := &blockingChecker{
+			Component: supervisortest.NewComponent("1", nil),
+			started:   make(chan struct{}),
+			cancelled: make(chan struct{}),
+		}
+		sv := supervisor.NewGroup(ctx, c1)
+		assert.NoError(t, sv.Open())
+		clock.BlockUntil(1)
+		clock.Advance(time.Second)
+		<-c1.started
+		assert.NoError(t, sv.Close())
+		<-c1.cancelled
+		assert.NoError(t, sv.Wait())
+	})
+	t.Run("disabled", func(t *testing.T) {
+		clock := supervisortest.NewClock(time.Now())
+		ctx := supervisor.WithHealthCheck(supervisor.WithClock(context.Background(), clock),
+			supervisor.HealthCheckConfig{
+				Disabled: true,
+			})
+		c1 := newCheckedComponent("1")
+		c1.setErr(errors.New("bang"))
+		sv := supervisor.NewGroup(ctx, c1)
+		assert.NoError(t, sv.Open())
+		clock.Advance(time.Hour)
+		assert.NoError(t, sv.Close())
+		assert.NoError(t, sv.Wait())
+	})
+}
@@ -0,0 +1,205 @@
+package supervisor
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Optional returns Component which failures are excluded from overall result
+// of HealthHandler. All components supervised by optional component are also
+// optional.
+func Optional(component Component) Component {
+	return &optionalComponent{
+		Component: component,
+	}
+}
+
+type optionalComponent struct {
+	Component
+}
+
+func (c *optionalComponent) Unwrap() Component {
+	return c.Component
+}
+
+/*
+HealthHandler serves liveness and readiness of supervisor tree over HTTP.
+HealthHandler walks tree on each request:
+
+	liveness fails if any opened component implementing Checker fails
+	check or any Stateful component is in StateFailed;
+
+	readiness additionally fails if any Stateful component is not in
+	StateOpen. Readiness of the root fails during shutdown to allow load
+	balancers to drain traffic before Close() is completed.
+
+Components which are not in StateOpen or which are supervised by not opened
+supervisor are not checked. Failures of Optional components are reported
+but excluded from overall result. Failed requests are answered with 503
+status code. Use "verbose" query parameter to get result of each component.
+*/
+type HealthHandler struct {
+	root Component
+
+	// Timeout limits checks of one request. Default is five seconds.
+	Timeout time.Duration
+}
+
+// NewHealthHandler returns new HealthHandler for tree with given root
+func NewHealthHandler(root Component) (h *HealthHandler) {
+	return &HealthHandler{
+		root:    root,
+		Timeout: time.Second * 5,
+	}
+}
+
+// ServeHTTP serves liveness on paths with "/healthz" or "/livez" suffix and
+// readiness on paths with "/readyz" suffix
+func (h *HealthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/healthz"), strings.HasSuffix(r.URL.Path, "/livez"):
+		h.serve(w, r, "healthz", false)
+	case strings.HasSuffix(r.URL.Path, "/readyz"):
+		h.serve(w, r, "readyz", true)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// Liveness returns liveness handler
+func (h *HealthHandler) Liveness() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.serve(w, r, "healthz", false)
+	})
+}
+
+// Readiness returns readiness handler
+func (h *HealthHandler) Readiness() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.serve(w, r, "readyz", true)
+	})
+}
+
+// healthResult is result of one component
+type healthResult struct {
+	path     string
+	optional bool
+	err      string
+}
+
+func (h *HealthHandler) serve(w http.ResponseWriter, r *http.Request, check string, readiness bool) {
+	ctx := r.Context()
+	if h.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.Timeout)
+		defer cancel()
+	}
+	ok, results := checkHealth(ctx, h.root, readiness)
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if !ok {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if _, verbose := r.URL.Query()["verbose"]; !verbose {
+		if ok {
+			w.Write([]byte("ok\n"))
+		} else {
+			w.Write([]byte("failed\n"))
+		}
+		return
+	}
+	var b strings.Builder
+	for _, res := range results {
+		switch {
+		case res.err == "":
+			b.WriteString("[+]" + res.path + " ok\n")
+		case res.optional:
+			b.WriteString("[~]" + res.path + " failed (optional): " + res.err + "\n")
+		default:
+			b.WriteString("[-]" + res.path + " failed: " + res.err + "\n")
+		}
+	}
+	if ok {
+		b.WriteString(check + " check passed\n")
+	} else {
+		b.WriteString(check + " check failed\n")
+	}
+	w.Write([]byte(b.String()))
+}
+
+// checkHealth checks liveness or readiness of tree with given root
+func checkHealth(ctx context.Context, root Component, readiness bool) (ok bool, results []healthResult) {
+	ok = true
+	walkHealth(root, NameOf(root), false, true, func(path string, component Component, optional, opened bool) bool {
+		res := healthResult{
+			path:     path,
+			optional: optional,
+		}
+		if stateful := statefulOf(component); stateful != nil {
+			state := stateful.State()
+			opened = opened && state == StateOpen
+			switch {
+			case state == StateFailed:
+				res.err = "state " + state.String()
+			case readiness && state != StateOpen:
+				res.err = "not ready: state " + state.String()
+			}
+		}
+		if checker := checkerOf(component); res.err == "" && opened && checker != nil {
+			if err := checker.Check(ctx); err != nil {
+				res.err = err.Error()
+			}
+		}
+		if res.err != "" && !optional {
+			ok = false
+		}
+		results = append(results, res)
+		return opened
+	})
+	return ok, results
+}
+
+// walkHealth calls given function for each node of tree with given root.
+// Function returns true if node is opened.
+func walkHealth(component Component, path string, optional, opened bool, fn func(path string, component Component, optional, opened bool) bool) {
+	if path == "" {
+		path = "root"
+	}
+	var children []Component
+	for c := component; c != nil; {
+		if _, ok := c.(*optionalComponent); ok {
+			optional = true
+		}
+		if sv, ok := c.(supervisor); ok {
+			children = sv.children()
+			break
+		}
+		wrapper, ok := c.(Wrapper)
+		if !ok {
+			break
+		}
+		c = wrapper.Unwrap()
+	}
+	opened = fn(path, component, optional, opened)
+	for index, child := range children {
+		walkHealth(child, path+PathSeparator+nameOf(child, index), optional, opened, fn)
+	}
+}
+
+// statefulOf returns Stateful implemented by given Component or any Component
+// wrapped by it
+func statefulOf(component Component) (stateful Stateful) {
+	for component != nil {
+		if stateful, ok := component.(Stateful); ok {
+			return stateful
+		}
+		wrapper, ok := component.(Wrapper)
+		if !ok {
+			return nil
+		}
+		component = wrapper.Unwrap()
+	}
+	return nil
+}
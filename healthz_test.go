@@ -0,0 +1,105 @@
+package supervisor_test
+
+import (
+	"context"
+	"errors"
+	"github.com/akaspin/supervisor"
+	"github.com/akaspin/supervisor/supervisortest"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func serveHealth(h http.Handler, target string) (code int, body string) {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec.Code, rec.Body.String()
+}
+
+func TestHealthHandler(t *testing.T) {
+	ctx := context.Background()
+	c1 := newCheckedComponent("1")
+	c2 := newCheckedComponent("2")
+	sv := supervisor.NewChain(supervisor.WithName(ctx, "app"),
+		c1,
+		supervisor.NewGroup(supervisor.WithName(ctx, "workers"),
+			supervisor.Optional(c2),
+		),
+	)
+	h := supervisor.NewHealthHandler(sv)
+
+	// checks are not drained before open: Check() of not opened component
+	// blocks request
+
+	code, body := serveHealth(h, "/readyz?verbose")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, ""+
+		"[-]app failed: not ready: state new\n"+
+		"[+]app/1 ok\n"+
+		"[-]app/workers failed: not ready: state new\n"+
+		"[+]app/workers/2 ok\n"+
+		"readyz check failed\n", body)
+	code, body = serveHealth(h, "/healthz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ok\n", body)
+
+	go func() {
+		for range c1.checks {
+		}
+	}()
+	go func() {
+		for range c2.checks {
+		}
+	}()
+	assert.NoError(t, sv.Open())
+	code, body = serveHealth(h.Readiness(), "/?verbose")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, ""+
+		"[+]app ok\n"+
+		"[+]app/1 ok\n"+
+		"[+]app/workers ok\n"+
+		"[+]app/workers/2 ok\n"+
+		"readyz check passed\n", body)
+
+	c2.setErr(errors.New("lagging"))
+	code, body = serveHealth(h, "/livez?verbose")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, body, "[~]app/workers/2 failed (optional): lagging\n")
+
+	c1.setErr(errors.New("stuck"))
+	code, body = serveHealth(h, "/healthz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "failed\n", body)
+	c1.setErr(nil)
+
+	// readiness fails during shutdown
+	block := make(chan struct{})
+	c3 := supervisortest.NewComponent("3", nil).
+		On(supervisor.PhaseClose, supervisortest.Script{Block: block})
+	sv2 := supervisor.NewGroup(context.Background(), c3)
+	h2 := supervisor.NewHealthHandler(sv2)
+	assert.NoError(t, sv2.Open())
+	code, _ = serveHealth(h2, "/readyz")
+	assert.Equal(t, http.StatusOK, code)
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		sv2.Close()
+	}()
+	for sv2.State() == supervisor.StateOpen {
+		time.Sleep(time.Millisecond)
+	}
+	code, body = serveHealth(h2, "/readyz?verbose")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Contains(t, body, "[-]root failed: not ready: state closing\n")
+	close(block)
+	<-closed
+	assert.NoError(t, sv2.Wait())
+
+	assert.NoError(t, sv.Close())
+	assert.NoError(t, sv.Wait())
+	code, _ = serveHealth(h, "/unknown")
+	assert.Equal(t, http.StatusNotFound, code)
+}
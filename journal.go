@@ -0,0 +1,203 @@
+package supervisor
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JournalRecord is one lifecycle event in Journal
+type JournalRecord struct {
+
+	// Time is wall time of event
+	Time time.Time `json:"time"`
+
+	// Mono is monotonic time of event since Journal creation
+	Mono time.Duration `json:"mono"`
+
+	// Component is name of supervised component
+	Component string `json:"component"`
+
+	// Path is path of supervised component
+	Path string `json:"path"`
+
+	// Event is one of events described in WithLogger
+	Event string `json:"event"`
+
+	// Phase is phase of event
+	Phase Phase `json:"phase"`
+
+	// Duration is duration of event phase if any
+	Duration time.Duration `json:"duration,omitempty"`
+
+	// Error is error of event if any
+	Error string `json:"error,omitempty"`
+
+	// Restart is restart counter of component. Restart is reserved for
+	// restarting supervisors and is always zero for supervisors provided by
+	// this package which never restart components.
+	Restart int `json:"restart"`
+}
+
+/*
+Journal writes lifecycle events of supervisor tree as JSON lines. Use
+WithJournal to record events of Chain, Group, Timeout and Trap. Journal
+records the same events as logged by supervisors. See WithLogger.
+
+Use ReadJournal to read written records and NewTimeline to reconstruct
+timeline of supervisor tree.
+*/
+type Journal struct {
+	clock Clock
+	start time.Time
+
+	mu  sync.Mutex
+	w   io.Writer
+	err error
+}
+
+// NewJournal returns new Journal which writes records to given Writer.
+// Journal uses Clock from given Context.
+func NewJournal(ctx context.Context, w io.Writer) (j *Journal) {
+	clock := ClockFrom(ctx)
+	return &Journal{
+		clock: clock,
+		start: clock.Now(),
+		w:     w,
+	}
+}
+
+// Err returns first write error. Journal keeps writing records after
+// write errors.
+func (j *Journal) Err() (err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.err
+}
+
+// Record writes given record. Record sets Time and Mono of record.
+func (j *Journal) Record(record JournalRecord) {
+	now := j.clock.Now()
+	record.Time = now
+	record.Mono = now.Sub(j.start)
+	buf, err := json.Marshal(record)
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err == nil {
+		_, err = j.w.Write(append(buf, '\n'))
+	}
+	if j.err == nil {
+		j.err = err
+	}
+}
+
+type journalKey struct{}
+
+// WithJournal returns copy of Context with given Journal. Chain, Group,
+// Timeout and Trap created with returned Context record lifecycle events to
+// Journal.
+func WithJournal(ctx context.Context, journal *Journal) context.Context {
+	return context.WithValue(ctx, journalKey{}, journal)
+}
+
+// ReadJournal reads all records from given Reader
+func ReadJournal(r io.Reader) (records []JournalRecord, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var record JournalRecord
+		if err = json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return records, err
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+// Timeline is timeline of supervisor tree node
+type Timeline struct {
+
+	// Name is name of node
+	Name string
+
+	// Path is path of node
+	Path string
+
+	// Records are records of node ordered by monotonic time
+	Records []JournalRecord
+
+	// Children are child nodes ordered by first record
+	Children []*Timeline
+}
+
+// NewTimeline reconstructs supervisor tree timeline from given records.
+// Returned root has empty name and path.
+func NewTimeline(records []JournalRecord) (root *Timeline) {
+	root = &Timeline{}
+	sorted := append([]JournalRecord(nil), records...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Mono < sorted[j].Mono
+	})
+	for _, record := range sorted {
+		node := root
+		if record.Path != "" {
+			for _, name := range strings.Split(record.Path, PathSeparator) {
+				node = node.child(name)
+			}
+		}
+		node.Records = append(node.Records, record)
+	}
+	return root
+}
+
+// child returns child node with given name
+func (t *Timeline) child(name string) (child *Timeline) {
+	for _, child = range t.Children {
+		if child.Name == name {
+			return child
+		}
+	}
+	child = &Timeline{
+		Name: name,
+		Path: joinPath(t.Path, name),
+	}
+	t.Children = append(t.Children, child)
+	return child
+}
+
+// String returns indented tree with records of each node
+func (t *Timeline) String() string {
+	var b strings.Builder
+	t.write(&b, 0)
+	return b.String()
+}
+
+func (t *Timeline) write(b *strings.Builder, depth int) {
+	indent := strings.Repeat("  ", depth)
+	if t.Path != "" {
+		b.WriteString(indent + t.Name + "\n")
+		indent += "  "
+		depth++
+	}
+	for _, record := range t.Records {
+		b.WriteString(indent + record.Mono.String() + " " + record.Event + " " + string(record.Phase))
+		if record.Duration > 0 {
+			b.WriteString(" (" + record.Duration.String() + ")")
+		}
+		if record.Error != "" {
+			b.WriteString(": " + record.Error)
+		}
+		b.WriteString("\n")
+	}
+	for _, child := range t.Children {
+		child.write(b, depth)
+	}
+}
@@ -0,0 +1,144 @@
+package supervisor_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/akaspin/supervisor"
+	"github.com/akaspin/supervisor/supervisortest"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestJournal(t *testing.T) {
+	var buf bytes.Buffer
+	clock := supervisortest.NewClock(time.Unix(0, 0))
+	ctx := supervisor.WithClock(context.Background(), clock)
+	journal := supervisor.NewJournal(ctx, &buf)
+	ctx = supervisor.WithName(supervisor.WithJournal(ctx, journal), "root")
+	c2 := supervisortest.NewComponent("2", nil).
+		On(supervisor.PhaseClose, supervisortest.Script{Err: errors.New("2")})
+	sv := supervisor.NewChain(ctx,
+		supervisor.NewGroup(supervisor.WithName(ctx, "group"),
+			supervisortest.NewComponent("1", nil),
+		),
+		c2,
+	)
+	assert.NoError(t, sv.Open())
+	assert.EqualError(t, sv.Close(), "2")
+	assert.NoError(t, sv.Wait())
+	assert.NoError(t, journal.Err())
+
+	records, err := supervisor.ReadJournal(&buf)
+	assert.NoError(t, err)
+	assert.Len(t, records, 9)
+	for _, record := range records {
+		assert.True(t, time.Unix(0, 0).Equal(record.Time))
+		assert.Zero(t, record.Restart)
+	}
+
+	timeline := supervisor.NewTimeline(records)
+	assert.Len(t, timeline.Children, 1)
+	root := timeline.Children[0]
+	assert.Equal(t, "root", root.Path)
+	assert.Len(t, root.Children, 2)
+	group, c2node := root.Children[0], root.Children[1]
+	assert.Equal(t, "root/group", group.Path)
+	assert.Equal(t, "root/2", c2node.Path)
+	assert.Len(t, group.Children, 1)
+	assert.Equal(t, "root/group/1", group.Children[0].Path)
+	for _, node := range []*supervisor.Timeline{group, group.Children[0], c2node} {
+		var events []string
+		for _, record := range node.Records {
+			events = append(events, record.Event+"-"+string(record.Phase)+"-"+record.Error)
+		}
+		expect := []string{"open-open-", "close-close-", "exit-wait-"}
+		if node == c2node {
+			expect[1] = "close-close-2"
+		}
+		assert.ElementsMatch(t, expect, events, node.Path)
+		assert.Equal(t, "open-open-", events[0], node.Path)
+	}
+	assert.Contains(t, timeline.String(), "  2\n    0s open open\n")
+}
+
+// flakyWriter fails first Write
+type flakyWriter struct {
+	bytes.Buffer
+	failed bool
+}
+
+func (w *flakyWriter) Write(p []byte) (n int, err error) {
+	if !w.failed {
+		w.failed = true
+		return 0, errors.New("flaky")
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestJournal_WriteError(t *testing.T) {
+	w := &flakyWriter{}
+	journal := supervisor.NewJournal(context.Background(), w)
+	journal.Record(supervisor.JournalRecord{Event: "1"})
+	journal.Record(supervisor.JournalRecord{Event: "2"})
+	assert.EqualError(t, journal.Err(), "flaky")
+	records, err := supervisor.ReadJournal(&w.Buffer)
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+	assert.Equal(t, "2", records[0].Event)
+}
+
+func TestRotatingFile(t *testing.T) {
+	dir, err := os.MkdirTemp("", "")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "journal")
+
+	f, err := supervisor.OpenRotatingFile(name, 4, 2)
+	assert.NoError(t, err)
+	for _, line := range []string{"1\n", "2\n", "3\n", "4\n", "5\n", "6\n", "7\n"} {
+		_, err = f.Write([]byte(line))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, f.Close())
+
+	for suffix, expect := range map[string]string{
+		"":   "7\n",
+		".1": "5\n6\n",
+		".2": "3\n4\n",
+	} {
+		data, err := os.ReadFile(name + suffix)
+		assert.NoError(t, err)
+		assert.Equal(t, expect, string(data), suffix)
+	}
+	_, err = os.Stat(name + ".3")
+	assert.True(t, os.IsNotExist(err))
+
+	t.Run("failed rotation", func(t *testing.T) {
+		name := filepath.Join(dir, "failed")
+		assert.NoError(t, os.MkdirAll(filepath.Join(name+".1", "blocker"), 0755))
+		f, err := supervisor.OpenRotatingFile(name, 2, 1)
+		assert.NoError(t, err)
+		_, err = f.Write([]byte("1\n"))
+		assert.NoError(t, err)
+		n, err := f.Write([]byte("2\n"))
+		assert.Error(t, err)
+		assert.Equal(t, 2, n)
+
+		assert.NoError(t, os.RemoveAll(name+".1"))
+		_, err = f.Write([]byte("3\n"))
+		assert.NoError(t, err)
+		assert.NoError(t, f.Close())
+		for suffix, expect := range map[string]string{
+			"":   "3\n",
+			".1": "1\n2\n",
+		} {
+			data, err := os.ReadFile(name + suffix)
+			assert.NoError(t, err)
+			assert.Equal(t, expect, string(data), suffix)
+		}
+	})
+}
@@ -0,0 +1,167 @@
+package supervisor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"regexp"
+	"runtime/pprof"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	// LabelSupervisor is goroutine label with ID of supervisor
+	LabelSupervisor = "supervisor"
+
+	// LabelComponent is goroutine label with name of supervised Component
+	LabelComponent = "component"
+)
+
+var lastSupervisorID uint64
+
+// newSupervisorID returns unique supervisor ID
+func newSupervisorID(kind string) string {
+	return kind + "-" + strconv.FormatUint(atomic.AddUint64(&lastSupervisorID, 1), 10)
+}
+
+// withLabels calls function with goroutine labels of supervised component.
+// All goroutines started by function inherit labels.
+func withLabels(ctx context.Context, id, name string, fn func()) {
+	pprof.Do(ctx, pprof.Labels(LabelSupervisor, id, LabelComponent, name), func(context.Context) {
+		fn()
+	})
+}
+
+// supervisor is implemented by supervisors which label own goroutines
+type supervisor interface {
+	supervisorID() string
+	supervisorKind() string // "chain", "group" or "timeout"
+	children() []Component
+}
+
+// Leak describes goroutines owned by supervised Component
+type Leak struct {
+
+	// Supervisor is ID of supervisor which started goroutines
+	Supervisor string
+
+	// Component is name of supervised Component. Component is empty for
+	// goroutines owned by supervisor itself.
+	Component string
+
+	// Count is number of goroutines with the same stack
+	Count int
+
+	// Stack is goroutines stack trace
+	Stack string
+}
+
+// String returns Leak description
+func (l Leak) String() string {
+	owner := l.Supervisor
+	if l.Component != "" {
+		owner += "/" + l.Component
+	}
+	return strconv.Itoa(l.Count) + " goroutine(s) owned by " + owner + "\n" + l.Stack
+}
+
+/*
+Leaks returns goroutines owned by supervisors in tree with given root. All
+goroutines started by supervisors provided by this package and goroutines
+started by Open(), Close() and Wait() methods of supervised components are
+attributed to supervised component.
+
+Leaks called after Wait() of the root is returned reports goroutines which
+are leaked by supervised components. Note that supervisors may finish own
+goroutines shortly after Wait() is returned.
+*/
+func Leaks(root Component) (leaks []Leak, err error) {
+	ids := map[string]struct{}{}
+	collectSupervisorIDs(root, ids)
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var buf bytes.Buffer
+	if err = pprof.Lookup("goroutine").WriteTo(&buf, 1); err != nil {
+		return nil, err
+	}
+	for _, leak := range parseGoroutines(buf.Bytes()) {
+		if _, ok := ids[leak.Supervisor]; ok {
+			leaks = append(leaks, leak)
+		}
+	}
+	return leaks, nil
+}
+
+func collectSupervisorIDs(component Component, ids map[string]struct{}) {
+	for component != nil {
+		if sv, ok := component.(supervisor); ok {
+			ids[sv.supervisorID()] = struct{}{}
+			for _, child := range sv.children() {
+				collectSupervisorIDs(child, ids)
+			}
+			return
+		}
+		wrapper, ok := component.(Wrapper)
+		if !ok {
+			return
+		}
+		component = wrapper.Unwrap()
+	}
+}
+
+var labelRe = regexp.MustCompile(`("(?:[^"\\]|\\.)*"):("(?:[^"\\]|\\.)*")`)
+
+// parseGoroutines parses goroutine profile in debug=1 format and returns
+// labelled goroutines
+func parseGoroutines(profile []byte) (leaks []Leak) {
+	scanner := bufio.NewScanner(bytes.NewReader(profile))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	var current *Leak
+	var stack []string
+	flush := func() {
+		if current != nil && current.Supervisor != "" {
+			current.Stack = strings.Join(stack, "\n")
+			leaks = append(leaks, *current)
+		}
+		current = nil
+		stack = nil
+	}
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			flush()
+		case strings.HasPrefix(line, "# labels: "):
+			if current == nil {
+				continue
+			}
+			for _, match := range labelRe.FindAllStringSubmatch(line, -1) {
+				key, _ := strconv.Unquote(match[1])
+				value, _ := strconv.Unquote(match[2])
+				switch key {
+				case LabelSupervisor:
+					current.Supervisor = value
+				case LabelComponent:
+					current.Component = value
+				}
+			}
+		case strings.HasPrefix(line, "#"):
+			if current != nil {
+				stack = append(stack, strings.TrimSpace(strings.TrimPrefix(line, "#")))
+			}
+		default:
+			if fields := strings.Fields(line); len(fields) > 1 && fields[1] == "@" {
+				flush()
+				count, _ := strconv.Atoi(fields[0])
+				current = &Leak{
+					Count: count,
+				}
+			}
+		}
+	}
+	flush()
+	return leaks
+}
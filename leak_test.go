@@ -0,0 +1,59 @@
+package supervisor_test
+
+import (
+	"context"
+	"github.com/akaspin/supervisor"
+	"github.com/akaspin/supervisor/supervisortest"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestLeaks(t *testing.T) {
+	t.Run("no leaks", func(t *testing.T) {
+		sv := supervisor.NewChain(context.Background(),
+			supervisortest.NewComponent("1", nil),
+			supervisor.NewGroup(context.Background(),
+				supervisortest.NewComponent("2", nil),
+				supervisor.NewTimeout(context.Background(), time.Second, supervisortest.NewComponent("3", nil)),
+			),
+		)
+		assert.NoError(t, sv.Open())
+		leaks, err := supervisor.Leaks(sv)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, leaks)
+
+		assert.NoError(t, sv.Close())
+		assert.NoError(t, sv.Wait())
+		supervisortest.VerifyNoLeaks(t, sv)
+	})
+	t.Run("leak", func(t *testing.T) {
+		block := make(chan struct{})
+		defer close(block)
+		clock := supervisortest.NewClock(time.Now())
+		ctx := supervisor.WithClock(context.Background(), clock)
+		hung := supervisortest.NewComponent("hung", nil).
+			On(supervisor.PhaseWait, supervisortest.Script{Block: block})
+		sv := supervisor.NewChain(ctx,
+			supervisortest.NewComponent("1", nil),
+			supervisor.NewTimeout(ctx, time.Second, hung),
+		)
+		assert.NoError(t, sv.Open())
+		go sv.Close()
+		clock.BlockUntil(1)
+		clock.Advance(time.Second)
+		assert.Equal(t, supervisor.ErrTimeout, sv.Wait())
+
+		leaks, err := supervisor.Leaks(sv)
+		assert.NoError(t, err)
+		var found bool
+		for _, leak := range leaks {
+			if leak.Component == "hung" {
+				found = true
+				assert.Contains(t, leak.Stack, "supervisortest.(*Component).Wait")
+				assert.Contains(t, leak.String(), "goroutine(s) owned by timeout-")
+			}
+		}
+		assert.True(t, found, "%v", leaks)
+	})
+}
@@ -0,0 +1,273 @@
+package supervisor
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+/*
+State is lifecycle state of Component. All components provided by this
+package share the same lifecycle:
+
+	StateNew -> StateOpening -> StateOpen -> StateClosing -> StateClosed
+	                                                      \-> StateFailed
+
+Out-of-order calls have well-defined results:
+
+	Close() before Open() moves Component to StateClosed. Subsequent Open()
+	returns ErrPrematurelyClosed and Wait() returns nil immediately.
+
+	Concurrent Open() calls are blocked until Component is opened and
+	return the same result.
+
+	Close() and Open() called after Component is closed return the same
+	results as first calls.
+
+Component which Open() is failed moves to StateClosing and then to
+StateFailed after all resources are released.
+*/
+type State uint32
+
+const (
+	// StateNew is initial State of Component
+	StateNew State = iota
+
+	// StateOpening means Open() of Component is in progress
+	StateOpening
+
+	// StateOpen means Component is successfully opened
+	StateOpen
+
+	// StateClosing means Component shutdown is in progress
+	StateClosing
+
+	// StateClosed means Component is exited without errors
+	StateClosed
+
+	// StateFailed means Component is exited with error
+	StateFailed
+)
+
+var stateNames = [...]string{
+	StateNew:     "new",
+	StateOpening: "opening",
+	StateOpen:    "open",
+	StateClosing: "closing",
+	StateClosed:  "closed",
+	StateFailed:  "failed",
+}
+
+// String returns State name
+func (s State) String() string {
+	if int(s) < len(stateNames) {
+		return stateNames[s]
+	}
+	return "unknown"
+}
+
+// IsTerminal returns true if State is StateClosed or StateFailed
+func (s State) IsTerminal() (ok bool) {
+	return s == StateClosed || s == StateFailed
+}
+
+// Transition is change of Component State
+type Transition struct {
+
+	// From is previous State
+	From State
+
+	// To is new State
+	To State
+
+	// Err is error caused transition to StateFailed
+	Err error
+
+	// Time is transition time
+	Time time.Time
+}
+
+// Stateful is implemented by Components which expose own lifecycle State.
+type Stateful interface {
+
+	// State returns current State of Component
+	State() (state State)
+
+	// Subscribe adds function which will be called on each State transition.
+	// Functions are called in order of transitions and may read State.
+	// Functions should not block. Returned function removes subscription.
+	Subscribe(fn func(transition Transition)) (unsubscribe func())
+}
+
+type subscriber struct {
+	id int
+	fn func(Transition)
+}
+
+// lifecycle is state machine shared by all components
+type lifecycle struct {
+	mu          sync.Mutex
+	state       State
+	opened      bool // lifecycle was in StateOpening
+	subscribers []subscriber
+	lastID      int
+	done        chan struct{} // closed on terminal state
+	clock       Clock
+
+	pending  []notification // transitions waiting for delivery
+	emitting bool           // pending transitions are being delivered
+}
+
+// notification is transition with subscribers at time of transition
+type notification struct {
+	transition  Transition
+	subscribers []subscriber
+}
+
+func newLifecycle(clock Clock) (l *lifecycle) {
+	return &lifecycle{
+		done:  make(chan struct{}),
+		clock: clock,
+	}
+}
+
+// State returns current State
+func (l *lifecycle) State() (state State) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.state
+}
+
+// Subscribe adds function which will be called on each State transition.
+// Functions are called in order of transitions without locks and may read
+// State. Functions should not block. Returned function removes subscription.
+func (l *lifecycle) Subscribe(fn func(transition Transition)) (unsubscribe func()) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lastID++
+	id := l.lastID
+	l.subscribers = append(l.subscribers, subscriber{id: id, fn: fn})
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		for i, sub := range l.subscribers {
+			if sub.id == id {
+				l.subscribers = append(l.subscribers[:i:i], l.subscribers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// transit moves lifecycle to given State if current State is one of given
+// and returns true. Error is passed to subscribers only for StateFailed.
+func (l *lifecycle) transit(to State, err error, from ...State) (ok bool) {
+	l.mu.Lock()
+	current := l.state
+	for _, state := range from {
+		if state == current {
+			ok = true
+			break
+		}
+	}
+	if !ok {
+		l.mu.Unlock()
+		return false
+	}
+	l.state = to
+	if to == StateOpening {
+		l.opened = true
+	}
+	if to != StateFailed {
+		err = nil
+	}
+	l.pending = append(l.pending, notification{
+		transition: Transition{
+			From: current,
+			To:   to,
+			Err:  err,
+			Time: l.clock.Now(),
+		},
+		subscribers: l.subscribers,
+	})
+	if l.emitting {
+		// notification will be delivered by current emitter
+		l.mu.Unlock()
+		return true
+	}
+	l.emitting = true
+	l.mu.Unlock()
+	l.emit()
+	return true
+}
+
+// emit delivers pending notifications in order of transitions. Subscribers
+// are called without lock.
+func (l *lifecycle) emit() {
+	for {
+		l.mu.Lock()
+		if len(l.pending) == 0 {
+			l.emitting = false
+			l.mu.Unlock()
+			return
+		}
+		n := l.pending[0]
+		l.pending = l.pending[1:]
+		l.mu.Unlock()
+		for _, sub := range n.subscribers {
+			sub.fn(n.transition)
+		}
+		if n.transition.To.IsTerminal() {
+			// notify after subscribers to guarantee delivery before Wait() exit
+			close(l.done)
+		}
+	}
+}
+
+// open moves lifecycle from StateNew to StateOpen for components which open
+// instantly. Open returns ErrPrematurelyClosed if given context is done before
+// open.
+func (l *lifecycle) open(ctx context.Context) (err error) {
+	if ctx.Err() == nil && l.transit(StateOpening, nil, StateNew) {
+		l.transit(StateOpen, nil, StateOpening)
+		return nil
+	}
+	if !l.isOpened() {
+		return ErrPrematurelyClosed
+	}
+	return nil
+}
+
+// closing moves opening or opened lifecycle to StateClosing
+func (l *lifecycle) closing() {
+	l.transit(StateClosing, nil, StateOpening, StateOpen)
+}
+
+// exit moves lifecycle to StateClosed or StateFailed if error is not nil.
+// Exit returns true if transition is happened.
+func (l *lifecycle) exit(err error) (ok bool) {
+	to := StateClosed
+	if err != nil {
+		to = StateFailed
+	}
+	return l.transit(to, err, StateNew, StateOpening, StateOpen, StateClosing)
+}
+
+// isOpened returns true if lifecycle was opened
+func (l *lifecycle) isOpened() (ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.opened
+}
+
+// isPrematurelyClosed returns true if lifecycle is closed before open
+func (l *lifecycle) isPrematurelyClosed() (ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.state.IsTerminal() && !l.opened
+}
+
+// doneChan returns channel which will be closed on terminal State
+func (l *lifecycle) doneChan() <-chan struct{} {
+	return l.done
+}
@@ -0,0 +1,204 @@
+package supervisor_test
+
+import (
+	"context"
+	"errors"
+	"github.com/akaspin/supervisor"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+type statefulComponent interface {
+	supervisor.Component
+	supervisor.Stateful
+}
+
+func newStatefulComponents() map[string]func() statefulComponent {
+	return map[string]func() statefulComponent{
+		"control": func() statefulComponent {
+			return supervisor.NewControl(context.Background())
+		},
+		"trap": func() statefulComponent {
+			return supervisor.NewTrap(context.Background())
+		},
+		"timeout": func() statefulComponent {
+			return supervisor.NewTimeout(context.Background(), time.Second, newTestingComponent("1", nil, nil, nil))
+		},
+		"chain": func() statefulComponent {
+			return supervisor.NewChain(context.Background(), newTestingComponent("1", nil, nil, nil))
+		},
+		"group": func() statefulComponent {
+			return supervisor.NewGroup(context.Background(), newTestingComponent("1", nil, nil, nil))
+		},
+	}
+}
+
+type transitionRecorder struct {
+	mu  sync.Mutex
+	res []string
+}
+
+func (r *transitionRecorder) record(transition supervisor.Transition) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.res = append(r.res, transition.From.String()+"-"+transition.To.String())
+}
+
+func (r *transitionRecorder) assert(t *testing.T, expect ...string) {
+	t.Helper()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	assert.Equal(t, expect, r.res)
+}
+
+func TestState_String(t *testing.T) {
+	assert.Equal(t, "new", supervisor.StateNew.String())
+	assert.Equal(t, "failed", supervisor.StateFailed.String())
+	assert.Equal(t, "unknown", supervisor.State(100).String())
+	assert.True(t, supervisor.StateClosed.IsTerminal())
+	assert.False(t, supervisor.StateClosing.IsTerminal())
+}
+
+func TestStateful_Cycle(t *testing.T) {
+	for name, factory := range newStatefulComponents() {
+		factory := factory
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			for i := 0; i < compositeTestIterations; i++ {
+				recorder := &transitionRecorder{}
+				c := factory()
+				c.Subscribe(recorder.record)
+				assert.Equal(t, supervisor.StateNew, c.State())
+				assert.NoError(t, c.Open())
+				assert.Equal(t, supervisor.StateOpen, c.State())
+				assert.NoError(t, c.Open())
+				assert.NoError(t, c.Close())
+				assert.NoError(t, c.Wait())
+				assert.Equal(t, supervisor.StateClosed, c.State())
+				recorder.assert(t, "new-opening", "opening-open", "open-closing", "closing-closed")
+			}
+		})
+	}
+}
+
+func TestStateful_CloseBeforeOpen(t *testing.T) {
+	for name, factory := range newStatefulComponents() {
+		factory := factory
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			for i := 0; i < compositeTestIterations; i++ {
+				recorder := &transitionRecorder{}
+				c := factory()
+				c.Subscribe(recorder.record)
+				assert.NoError(t, c.Close())
+				assert.Equal(t, supervisor.StateClosed, c.State())
+				assert.Equal(t, supervisor.ErrPrematurelyClosed, c.Open())
+				assert.NoError(t, c.Wait())
+				assert.NoError(t, c.Close())
+				assert.Equal(t, supervisor.StateClosed, c.State())
+				recorder.assert(t, "new-closed")
+			}
+		})
+	}
+}
+
+func TestStateful_Failed(t *testing.T) {
+	t.Run("wait", func(t *testing.T) {
+		recorder := &transitionRecorder{}
+		var failErr error
+		c1 := newTestingComponent("1", nil, nil, errors.New("1"))
+		sv := supervisor.NewChain(context.Background(), c1)
+		sv.Subscribe(recorder.record)
+		sv.Subscribe(func(transition supervisor.Transition) {
+			if transition.To == supervisor.StateFailed {
+				failErr = transition.Err
+			}
+		})
+		assert.NoError(t, sv.Open())
+		close(c1.closedChan)
+		assert.EqualError(t, sv.Wait(), "1")
+		assert.Equal(t, supervisor.StateFailed, sv.State())
+		assert.EqualError(t, failErr, "1")
+		recorder.assert(t, "new-opening", "opening-open", "open-closing", "closing-failed")
+	})
+	t.Run("open", func(t *testing.T) {
+		recorder := &transitionRecorder{}
+		c1 := newTestingComponent("1", errors.New("1"), nil, nil)
+		to := supervisor.NewTimeout(context.Background(), time.Second, c1)
+		to.Subscribe(recorder.record)
+		assert.EqualError(t, to.Open(), "1")
+		assert.EqualError(t, to.Open(), "1")
+		assert.NoError(t, to.Close())
+		assert.NoError(t, to.Wait())
+		assert.Equal(t, supervisor.StateFailed, to.State())
+		recorder.assert(t, "new-opening", "opening-closing", "closing-failed")
+	})
+}
+
+func TestStateful_Unsubscribe(t *testing.T) {
+	recorder := &transitionRecorder{}
+	c := supervisor.NewControl(context.Background())
+	unsubscribe := c.Subscribe(recorder.record)
+	assert.NoError(t, c.Open())
+	unsubscribe()
+	assert.NoError(t, c.Close())
+	assert.NoError(t, c.Wait())
+	recorder.assert(t, "new-opening", "opening-open")
+}
+
+func TestStateful_SubscriberReadsState(t *testing.T) {
+	c := supervisor.NewControl(context.Background())
+	var states []supervisor.State
+	release := make(chan struct{})
+	c.Subscribe(func(transition supervisor.Transition) {
+		states = append(states, c.State())
+		if transition.To == supervisor.StateOpen {
+			<-release
+		}
+	})
+	openChan := make(chan error, 1)
+	go func() {
+		openChan <- c.Open()
+	}()
+
+	// blocked subscriber does not block State() and other transitions
+	for c.State() != supervisor.StateOpen {
+		time.Sleep(time.Millisecond)
+	}
+	closeChan := make(chan error, 1)
+	go func() {
+		closeChan <- c.Close()
+	}()
+	for c.State() != supervisor.StateClosed {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	assert.NoError(t, <-openChan)
+	assert.NoError(t, <-closeChan)
+	assert.NoError(t, c.Wait())
+	assert.Equal(t, []supervisor.State{
+		supervisor.StateOpening,
+		supervisor.StateOpen,
+		supervisor.StateClosed,
+		supervisor.StateClosed,
+	}, states)
+}
+
+func TestTimeout_ConcurrentOpen(t *testing.T) {
+	c1 := newTestingComponent("1", nil, nil, nil)
+	to := supervisor.NewTimeout(context.Background(), time.Second, c1)
+	var wg sync.WaitGroup
+	wg.Add(3)
+	for i := 0; i < 3; i++ {
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, to.Open())
+		}()
+	}
+	wg.Wait()
+	assert.NoError(t, to.Close())
+	assert.NoError(t, to.Wait())
+	c1.assertCycle(t)
+}
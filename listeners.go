@@ -0,0 +1,267 @@
+//go:build !windows
+
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"github.com/akaspin/errslice"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+const (
+	// listenFDsStart is first inherited file descriptor
+	listenFDsStart = 3
+)
+
+var (
+	// ErrNoListener is returned by Listeners if listener with given name is
+	// not found or Listeners is not open
+	ErrNoListener = errors.New("listener not found")
+)
+
+// ListenerConfig describes one listener of Listeners
+type ListenerConfig struct {
+
+	// Name is name of listener. Name is matched with LISTEN_FDNAMES.
+	Name string
+
+	// Network is network passed to net.Listen(). Default is "tcp".
+	Network string
+
+	// Address is address passed to net.Listen(). If Address is empty
+	// listener should be inherited.
+	Address string
+}
+
+/*
+Listeners holds listeners for downstream components. Place Listeners before
+consumers in Chain and call Listener() in Open() of consumers.
+
+On Open() Listeners picks up listeners inherited with socket activation
+protocol (LISTEN_FDS, LISTEN_FDNAMES and LISTEN_PID environment variables)
+and creates listeners which are not inherited. Unlike systemd LISTEN_PID is
+optional: inherited environment is cleared on first use.
+
+Each call of Listener() returns independent duplicate. Listeners closes own
+listeners only after Close() and after all returned duplicates are closed.
+Sockets stay open and keep pending connections until last descriptor is
+closed.
+*/
+type Listeners struct {
+	*Control
+	configs []ListenerConfig
+
+	mu        sync.Mutex
+	names     []string
+	listeners map[string]net.Listener
+	stopping  bool
+	refs      sync.WaitGroup
+}
+
+// NewListeners returns new Listeners with given configs. Listeners uses name
+// from given Context.
+func NewListeners(ctx context.Context, configs ...ListenerConfig) (l *Listeners) {
+	return &Listeners{
+		Control:   NewControl(ctx),
+		configs:   configs,
+		listeners: map[string]net.Listener{},
+	}
+}
+
+// Open inherits or creates listeners. Repeated calls return the result of
+// the first call.
+func (l *Listeners) Open() (err error) {
+	return l.openWith(l.open)
+}
+
+func (l *Listeners) open() (err error) {
+	l.mu.Lock()
+	for _, config := range l.configs {
+		var ln net.Listener
+		if ln, err = listen(config); err != nil {
+			break
+		}
+		l.names = append(l.names, config.Name)
+		l.listeners[config.Name] = ln
+	}
+	l.mu.Unlock()
+	if err != nil {
+		l.closeListeners()
+		l.Fail(err)
+		return err
+	}
+	l.Go(func(ctx context.Context) (err error) {
+		<-ctx.Done()
+		l.mu.Lock()
+		l.stopping = true
+		l.mu.Unlock()
+		l.refs.Wait()
+		return l.closeListeners()
+	})
+	return nil
+}
+
+// Names returns names of listeners in order of configs
+func (l *Listeners) Names() (names []string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.names...)
+}
+
+// Listener returns duplicate of listener with given name. Caller should
+// close returned listener.
+func (l *Listeners) Listener(name string) (ln net.Listener, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	original, ok := l.listeners[name]
+	if !ok || l.stopping {
+		return nil, ErrNoListener
+	}
+	f, err := listenerFile(original)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if ln, err = net.FileListener(f); err != nil {
+		return nil, err
+	}
+	l.refs.Add(1)
+	return &sharedListener{
+		Listener: ln,
+		release:  l.refs.Done,
+	}, nil
+}
+
+// Files returns duplicates of listener descriptors in order of Names(). Pass
+// files to child process with socket activation protocol. Caller should
+// close returned files. After Files() Listeners does not remove files of
+// unix sockets on close.
+func (l *Listeners) Files() (files []*os.File, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.stopping {
+		return nil, ErrNoListener
+	}
+	for _, name := range l.names {
+		ln := l.listeners[name]
+		if unix, ok := ln.(*net.UnixListener); ok {
+			unix.SetUnlinkOnClose(false)
+		}
+		var f *os.File
+		if f, err = listenerFile(ln); err != nil {
+			for _, f := range files {
+				f.Close()
+			}
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+func (l *Listeners) closeListeners() (err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, name := range l.names {
+		err = errslice.Append(err, l.listeners[name].Close())
+		delete(l.listeners, name)
+	}
+	l.names = nil
+	return err
+}
+
+// sharedListener releases reference on close
+type sharedListener struct {
+	net.Listener
+	once    sync.Once
+	release func()
+}
+
+func (l *sharedListener) Close() (err error) {
+	err = l.Listener.Close()
+	l.once.Do(l.release)
+	return err
+}
+
+// listen returns inherited listener or creates new one
+func listen(config ListenerConfig) (ln net.Listener, err error) {
+	if ln, err = inheritedListener(config.Name); ln != nil || err != nil {
+		return ln, err
+	}
+	if config.Address == "" {
+		return nil, errors.New("listener " + config.Name + " is not inherited")
+	}
+	network := config.Network
+	if network == "" {
+		network = "tcp"
+	}
+	return net.Listen(network, config.Address)
+}
+
+// listenerFile returns duplicate of listener descriptor
+func listenerFile(ln net.Listener) (f *os.File, err error) {
+	filer, ok := ln.(interface {
+		File() (*os.File, error)
+	})
+	if !ok {
+		return nil, errors.New("listener " + ln.Addr().String() + " has no descriptor")
+	}
+	return filer.File()
+}
+
+// inherited holds listeners inherited with socket activation protocol
+var inherited struct {
+	once      sync.Once
+	mu        sync.Mutex
+	listeners map[string]net.Listener
+	errs      map[string]error
+}
+
+// inheritedListener takes inherited listener with given name
+func inheritedListener(name string) (ln net.Listener, err error) {
+	inherited.once.Do(inheritListeners)
+	inherited.mu.Lock()
+	defer inherited.mu.Unlock()
+	ln, err = inherited.listeners[name], inherited.errs[name]
+	delete(inherited.listeners, name)
+	delete(inherited.errs, name)
+	return ln, err
+}
+
+// inheritListeners reads and clears socket activation environment. Unnamed
+// descriptors are named by index.
+func inheritListeners() {
+	inherited.listeners = map[string]net.Listener{}
+	inherited.errs = map[string]error{}
+	pid := os.Getenv("LISTEN_PID")
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+	if err != nil || n <= 0 || (pid != "" && pid != strconv.Itoa(os.Getpid())) {
+		return
+	}
+	for i := 0; i < n; i++ {
+		fd := listenFDsStart + i
+		syscall.CloseOnExec(fd)
+		name := strconv.Itoa(i)
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+		f := os.NewFile(uintptr(fd), name)
+		ln, err := net.FileListener(f)
+		f.Close()
+		if err != nil {
+			inherited.errs[name] = err
+			continue
+		}
+		inherited.listeners[name] = ln
+	}
+}
@@ -0,0 +1,139 @@
+//go:build !windows
+
+package supervisor_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/akaspin/supervisor"
+	"github.com/akaspin/supervisor/supervisortest"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+const listenersHelperEnv = "SUPERVISOR_LISTENERS_HELPER"
+
+// TestListenersHelper is run with inherited listener by TestListeners
+func TestListenersHelper(t *testing.T) {
+	if os.Getenv(listenersHelperEnv) == "" {
+		t.Skip("helper process")
+	}
+	l := supervisor.NewListeners(context.Background(), supervisor.ListenerConfig{
+		Name: "http",
+	})
+	if err := l.Open(); err != nil {
+		fmt.Print(err)
+		os.Exit(1)
+	}
+	ln, err := l.Listener("http")
+	if err != nil {
+		fmt.Print(err)
+		os.Exit(1)
+	}
+	fmt.Println(ln.Addr().String() + " LISTEN_FDS=" + os.Getenv("LISTEN_FDS"))
+	conn, err := ln.Accept()
+	if err != nil {
+		os.Exit(1)
+	}
+	conn.Write([]byte("inherited"))
+	conn.Close()
+	ln.Close()
+	l.Close()
+	l.Wait()
+}
+
+func TestListeners(t *testing.T) {
+	t.Run("consumers", func(t *testing.T) {
+		l := supervisor.NewListeners(context.Background(), supervisor.ListenerConfig{
+			Name:    "http",
+			Address: "127.0.0.1:0",
+		})
+		assert.NoError(t, l.Open())
+		assert.Equal(t, []string{"http"}, l.Names())
+		_, err := l.Listener("missing")
+		assert.Equal(t, supervisor.ErrNoListener, err)
+		ln, err := l.Listener("http")
+		assert.NoError(t, err)
+
+		assert.NoError(t, l.Close())
+		waitChan := make(chan error, 1)
+		go func() {
+			waitChan <- l.Wait()
+		}()
+		select {
+		case <-waitChan:
+			t.Fatal("listeners closed before consumer")
+		case <-time.After(time.Millisecond * 50):
+		}
+		_, err = l.Listener("http")
+		assert.Equal(t, supervisor.ErrNoListener, err)
+
+		// socket is still accepting
+		conn, err := net.Dial("tcp", ln.Addr().String())
+		assert.NoError(t, err)
+		accepted, err := ln.Accept()
+		assert.NoError(t, err)
+		accepted.Close()
+		conn.Close()
+
+		assert.NoError(t, ln.Close())
+		assert.NoError(t, <-waitChan)
+		_, err = net.Dial("tcp", ln.Addr().String())
+		assert.Error(t, err)
+	})
+	t.Run("not inherited", func(t *testing.T) {
+		l := supervisor.NewListeners(context.Background(), supervisor.ListenerConfig{
+			Name: "http",
+		})
+		assert.EqualError(t, l.Open(), "listener http is not inherited")
+		assert.Error(t, l.Wait())
+	})
+	t.Run("inherited", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.NoError(t, err)
+		f, err := ln.(*net.TCPListener).File()
+		assert.NoError(t, err)
+		ln.Close()
+
+		var out bytes.Buffer
+		cmd := exec.Command(os.Args[0], "-test.run=TestListenersHelper")
+		cmd.Env = append(os.Environ(),
+			listenersHelperEnv+"=1",
+			"LISTEN_FDS=1",
+			"LISTEN_FDNAMES=http",
+		)
+		cmd.ExtraFiles = []*os.File{f}
+		cmd.Stdout = &out
+		assert.NoError(t, cmd.Start())
+		f.Close()
+
+		conn, err := net.Dial("tcp", ln.Addr().String())
+		assert.NoError(t, err)
+		buf := make([]byte, 9)
+		conn.SetReadDeadline(time.Now().Add(time.Second * 5))
+		_, err = conn.Read(buf)
+		assert.NoError(t, err)
+		assert.Equal(t, "inherited", string(buf))
+		conn.Close()
+		assert.NoError(t, cmd.Wait())
+		assert.Contains(t, out.String(), ln.Addr().String()+" LISTEN_FDS=\n")
+	})
+}
+
+func TestListeners_Conformance(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	address := ln.Addr().String()
+	assert.NoError(t, ln.Close())
+	supervisortest.Conformance(t, func() supervisor.Component {
+		return supervisor.NewListeners(context.Background(), supervisor.ListenerConfig{
+			Name:    "http",
+			Address: address,
+		})
+	})
+}
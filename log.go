@@ -0,0 +1,160 @@
+package supervisor
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Log attribute keys
+const (
+	LogComponent = "component"
+	LogPath      = "path"
+	LogPhase     = "phase"
+	LogDuration  = "duration"
+	LogError     = "error"
+)
+
+type loggerKey struct{}
+
+type componentLoggerKey struct{}
+
+/*
+WithLogger returns copy of Context with given Logger. Chain, Group, Timeout
+and Trap created with returned Context log lifecycle events of supervised
+components:
+
+	open    Open() is successfully returned;
+	close   Close() is returned;
+	exit    Wait() is returned;
+	fail    component initiated shutdown by failed Open(), exit before
+	        Close() or error passed to Trap;
+	timeout Timeout is exceeded.
+
+Events have attributes with component name, path, phase, duration and
+error. Events with errors are logged with error level.
+
+Chain and Group also inject child Logger with name and path of each
+supervised Control into Control context. See LoggerFrom.
+*/
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// LoggerFrom returns Logger from given Context. If Context is Control
+// context LoggerFrom returns child Logger injected by supervisor with name
+// and path of Control. Otherwise LoggerFrom returns Logger with path from
+// Context. If Context has no Logger LoggerFrom returns Logger which discards
+// all records.
+func LoggerFrom(ctx context.Context) (logger *slog.Logger) {
+	if logger, _ = ctx.Value(componentLoggerKey{}).(*slog.Logger); logger != nil {
+		return logger
+	}
+	logger, _ = ctx.Value(loggerKey{}).(*slog.Logger)
+	if logger == nil {
+		return slog.New(slog.DiscardHandler)
+	}
+	if path := PathFrom(ctx); path != "" {
+		return logger.With(slog.String(LogPath, path))
+	}
+	return logger
+}
+
+// loggerInjector is implemented by Components which accept child Logger from
+// supervisor
+type loggerInjector interface {
+	injectLogger(logger *slog.Logger)
+}
+
+// injectLoggers injects child Loggers with name and path of supervised
+// components to given components or components wrapped by them
+func injectLoggers(ctx context.Context, components []Component) []Component {
+	logger, _ := ctx.Value(loggerKey{}).(*slog.Logger)
+	if logger == nil {
+		return components
+	}
+	path := PathFrom(ctx)
+	for index, component := range components {
+		name := nameOf(component, index)
+		injectLogger(component, logger.With(
+			slog.String(LogComponent, name),
+			slog.String(LogPath, joinPath(path, name)),
+		))
+	}
+	return components
+}
+
+// injectLogger injects given Logger to first Component which accepts it
+// starting from given Component
+func injectLogger(component Component, logger *slog.Logger) {
+	for component != nil {
+		if injector, ok := component.(loggerInjector); ok {
+			injector.injectLogger(logger)
+			return
+		}
+		wrapper, ok := component.(Wrapper)
+		if !ok {
+			return
+		}
+		component = wrapper.Unwrap()
+	}
+}
+
+// eventLogger logs lifecycle events of supervised components to Logger and
+// Journal
+type eventLogger struct {
+	logger  *slog.Logger // nil if logging is disabled
+	journal *Journal     // nil if journal is disabled
+	path    string
+}
+
+func newEventLogger(ctx context.Context) (l eventLogger) {
+	l.logger, _ = ctx.Value(loggerKey{}).(*slog.Logger)
+	l.journal, _ = ctx.Value(journalKey{}).(*Journal)
+	l.path = PathFrom(ctx)
+	return l
+}
+
+// log logs event of supervised component with given name. Supervised
+// components are logged with path relative to supervisor. Use empty name
+// to log own events.
+func (l eventLogger) log(msg, name string, phase Phase, duration time.Duration, err error) {
+	if l.logger == nil && l.journal == nil {
+		return
+	}
+	path := l.path
+	if name != "" {
+		path = joinPath(l.path, name)
+	} else {
+		name = baseName(l.path)
+	}
+	if l.journal != nil {
+		l.journal.Record(JournalRecord{
+			Component: name,
+			Path:      path,
+			Event:     msg,
+			Phase:     phase,
+			Duration:  duration,
+			Error:     errorString(err),
+		})
+	}
+	if l.logger == nil {
+		return
+	}
+	level := slog.LevelInfo
+	attrs := []slog.Attr{
+		slog.String(LogComponent, name),
+		slog.String(LogPath, path),
+		slog.String(LogPhase, string(phase)),
+	}
+	if duration > 0 {
+		attrs = append(attrs, slog.Duration(LogDuration, duration))
+	}
+	if err != nil || msg == "fail" || msg == "timeout" {
+		level = slog.LevelError
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String(LogError, err.Error()))
+	}
+	l.logger.LogAttrs(context.Background(), level, msg, attrs...)
+}
@@ -0,0 +1,156 @@
+package supervisor_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/akaspin/supervisor"
+	"github.com/akaspin/supervisor/supervisortest"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// logBuffer is concurrent-safe buffer for slog.TextHandler
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (n int, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// lines returns logged lines with given message
+func (b *logBuffer) lines(msg string) (res []string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, line := range strings.Split(b.buf.String(), "\n") {
+		if strings.Contains(line, "msg="+msg+" ") {
+			res = append(res, line)
+		}
+	}
+	return res
+}
+
+func newTestLogger(buf *logBuffer) *slog.Logger {
+	return slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey || a.Key == supervisor.LogDuration {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+}
+
+func TestWithLogger(t *testing.T) {
+	t.Run("chain", func(t *testing.T) {
+		buf := &logBuffer{}
+		ctx := supervisor.WithLogger(context.Background(), newTestLogger(buf))
+		ctx = supervisor.WithName(ctx, "root")
+		c2 := supervisortest.NewComponent("2", nil).
+			On(supervisor.PhaseWait, supervisortest.Script{Err: errors.New("2")})
+		sv := supervisor.NewChain(ctx,
+			supervisor.NewGroup(supervisor.WithName(ctx, "group"),
+				supervisortest.NewComponent("1", nil),
+			),
+			c2,
+		)
+		assert.NoError(t, sv.Open())
+		c2.Exit()
+		assert.EqualError(t, sv.Wait(), "2")
+		assert.Equal(t, []string{
+			`level=INFO msg=open component=1 path=root/group/1 phase=open`,
+			`level=INFO msg=open component=group path=root/group phase=open`,
+			`level=INFO msg=open component=2 path=root/2 phase=open`,
+		}, buf.lines("open"))
+		assert.Equal(t, []string{
+			`level=ERROR msg=fail component=2 path=root/2 phase=wait error=2`,
+		}, buf.lines("fail"))
+		assert.Contains(t, buf.lines("exit"),
+			`level=ERROR msg=exit component=2 path=root/2 phase=wait error=2`)
+		assert.Contains(t, buf.lines("close"),
+			`level=INFO msg=close component=1 path=root/group/1 phase=close`)
+	})
+	t.Run("group open failure", func(t *testing.T) {
+		buf := &logBuffer{}
+		ctx := supervisor.WithLogger(context.Background(), newTestLogger(buf))
+		sv := supervisor.NewGroup(ctx,
+			supervisortest.NewComponent("1", nil).
+				On(supervisor.PhaseOpen, supervisortest.Script{Err: errors.New("1")}),
+		)
+		assert.EqualError(t, sv.Open(), "1")
+		assert.NoError(t, sv.Wait())
+		assert.Equal(t, []string{
+			`level=ERROR msg=fail component=1 path=1 phase=open error=1`,
+		}, buf.lines("fail"))
+	})
+	t.Run("timeout", func(t *testing.T) {
+		buf := &logBuffer{}
+		clock := supervisortest.NewClock(time.Now())
+		ctx := supervisor.WithLogger(supervisor.WithClock(context.Background(), clock), newTestLogger(buf))
+		block := make(chan struct{})
+		defer close(block)
+		c1 := supervisortest.NewComponent("1", nil).
+			On(supervisor.PhaseWait, supervisortest.Script{Block: block})
+		to := supervisor.NewTimeout(supervisor.WithName(ctx, "root"), time.Second, c1)
+		assert.Equal(t, "root", to.Name())
+		assert.NoError(t, to.Open())
+		assert.NoError(t, to.Close())
+		clock.Advance(time.Second)
+		assert.EqualError(t, to.Wait(), supervisor.ErrTimeout.Error())
+		assert.Equal(t, []string{
+			`level=ERROR msg=timeout component=1 path=root/1 phase=wait error="timeout exceeded"`,
+		}, buf.lines("timeout"))
+	})
+	t.Run("trap", func(t *testing.T) {
+		buf := &logBuffer{}
+		ctx := supervisor.WithLogger(context.Background(), newTestLogger(buf))
+		trap := supervisor.NewTrap(supervisor.WithName(ctx, "trap"))
+		assert.NoError(t, trap.Open())
+		trap.Trap(errors.New("bang"))
+		assert.EqualError(t, trap.Wait(), "bang")
+		assert.Equal(t, []string{
+			`level=ERROR msg=fail component=trap path=trap phase=wait error=bang`,
+		}, buf.lines("fail"))
+	})
+	t.Run("component logger", func(t *testing.T) {
+		buf := &logBuffer{}
+		ctx := supervisor.WithLogger(context.Background(), newTestLogger(buf))
+		c1 := supervisor.NewControl(supervisor.WithName(ctx, "1"))
+		sv := supervisor.NewChain(supervisor.WithName(ctx, "root"),
+			supervisor.NewTimeout(ctx, time.Second, c1),
+		)
+		assert.NoError(t, sv.Open())
+		supervisor.LoggerFrom(c1.Ctx()).Info("hello")
+		assert.NoError(t, sv.Close())
+		assert.NoError(t, sv.Wait())
+		assert.Equal(t, []string{`level=INFO msg=hello component=1 path=root/1`}, buf.lines("hello"))
+	})
+	t.Run("captured context", func(t *testing.T) {
+		buf := &logBuffer{}
+		ctx := supervisor.WithLogger(context.Background(), newTestLogger(buf))
+		c1 := supervisor.NewControl(supervisor.WithName(ctx, "1"))
+		captured := c1.Ctx()
+		go supervisor.LoggerFrom(c1.Ctx()).Info("race")
+		sv := supervisor.NewGroup(supervisor.WithName(ctx, "root"), c1)
+		assert.NoError(t, sv.Open())
+		supervisor.LoggerFrom(captured).Info("hello")
+		assert.NoError(t, sv.Close())
+		assert.NoError(t, sv.Wait())
+		assert.Equal(t, []string{`level=INFO msg=hello component=1 path=root/1`}, buf.lines("hello"))
+	})
+	t.Run("logger from", func(t *testing.T) {
+		buf := &logBuffer{}
+		ctx := supervisor.WithLogger(context.Background(), newTestLogger(buf))
+		supervisor.LoggerFrom(supervisor.WithName(ctx, "a")).Info("hello")
+		supervisor.LoggerFrom(context.Background()).Info("hello")
+		assert.Equal(t, []string{`level=INFO msg=hello path=a`}, buf.lines("hello"))
+	})
+}
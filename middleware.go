@@ -0,0 +1,142 @@
+package supervisor
+
+import (
+	"context"
+	"strings"
+)
+
+// PathSeparator separates names in paths of supervised Components
+const PathSeparator = "/"
+
+/*
+Middleware decorates supervised Component. Use WithMiddleware to apply
+Middlewares to all children of Chain and Group.
+
+Component passed to Middleware is Child or Wrapper of Child. Use NameOf and
+PathOf to get name and path of supervised Component.
+*/
+type Middleware func(component Component) Component
+
+type middlewareKey struct{}
+
+// WithMiddleware returns copy of Context with given Middlewares appended to
+// Middlewares from parent Context. Chain and Group created with returned
+// Context apply all Middlewares to each child on construction. First
+// Middleware is the outermost.
+func WithMiddleware(ctx context.Context, middlewares ...Middleware) context.Context {
+	parent := middlewaresFrom(ctx)
+	stack := make([]Middleware, 0, len(parent)+len(middlewares))
+	stack = append(stack, parent...)
+	stack = append(stack, middlewares...)
+	return context.WithValue(ctx, middlewareKey{}, stack)
+}
+
+func middlewaresFrom(ctx context.Context) (middlewares []Middleware) {
+	middlewares, _ = ctx.Value(middlewareKey{}).([]Middleware)
+	return middlewares
+}
+
+type nameKey struct{}
+
+// WithName returns copy of Context with given name appended to path.
+// Chain, Group, Timeout, Trap and Control created with returned Context have
+// given name. Path of children of Chain and Group is prefixed by path from
+// Context.
+func WithName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, nameKey{}, joinPath(PathFrom(ctx), name))
+}
+
+// PathFrom returns path from given Context. PathFrom returns empty string
+// if Context has no name.
+func PathFrom(ctx context.Context) (path string) {
+	path, _ = ctx.Value(nameKey{}).(string)
+	return path
+}
+
+func joinPath(parent, name string) (path string) {
+	if parent == "" {
+		return name
+	}
+	return parent + PathSeparator + name
+}
+
+// baseName returns last name in path
+func baseName(path string) (name string) {
+	return path[strings.LastIndex(path, PathSeparator)+1:]
+}
+
+// Child is supervised Component with its name and path in supervisor tree
+type Child struct {
+	Component
+	name string
+	path string
+}
+
+// Name returns name of supervised Component
+func (c *Child) Name() string {
+	return c.name
+}
+
+// Path returns path of supervised Component
+func (c *Child) Path() string {
+	return c.path
+}
+
+// Unwrap returns supervised Component
+func (c *Child) Unwrap() Component {
+	return c.Component
+}
+
+// NameOf returns name of given Component. NameOf returns empty string if
+// Component and all Components wrapped by it are not Named.
+func NameOf(component Component) (name string) {
+	for component != nil {
+		if named, ok := component.(Named); ok && named.Name() != "" {
+			return named.Name()
+		}
+		wrapper, ok := component.(Wrapper)
+		if !ok {
+			break
+		}
+		component = wrapper.Unwrap()
+	}
+	return ""
+}
+
+// PathOf returns path of given Component if it is Child or wraps Child.
+// Otherwise PathOf returns NameOf(component).
+func PathOf(component Component) (path string) {
+	for c := component; c != nil; {
+		if child, ok := c.(*Child); ok {
+			return child.path
+		}
+		wrapper, ok := c.(Wrapper)
+		if !ok {
+			break
+		}
+		c = wrapper.Unwrap()
+	}
+	return NameOf(component)
+}
+
+// applyMiddlewares wraps components by Middlewares from given Context
+func applyMiddlewares(ctx context.Context, components []Component) (res []Component) {
+	middlewares := middlewaresFrom(ctx)
+	if len(middlewares) == 0 {
+		return components
+	}
+	path := PathFrom(ctx)
+	res = make([]Component, len(components))
+	for index, component := range components {
+		name := nameOf(component, index)
+		res[index] = &Child{
+			Component: component,
+			name:      name,
+			path:      joinPath(path, name),
+		}
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			res[index] = middlewares[i](res[index])
+		}
+	}
+	return res
+}
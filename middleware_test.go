@@ -0,0 +1,77 @@
+package supervisor_test
+
+import (
+	"context"
+	"github.com/akaspin/supervisor"
+	"github.com/akaspin/supervisor/supervisortest"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type middlewareComponent struct {
+	supervisor.Component
+	recorder *supervisortest.Recorder
+	prefix   string
+}
+
+func (c *middlewareComponent) Unwrap() supervisor.Component {
+	return c.Component
+}
+
+func (c *middlewareComponent) Open() (err error) {
+	c.recorder.Record(c.prefix + "-" + supervisor.PathOf(c))
+	return c.Component.Open()
+}
+
+func recordingMiddleware(recorder *supervisortest.Recorder, prefix string) supervisor.Middleware {
+	return func(component supervisor.Component) supervisor.Component {
+		return &middlewareComponent{
+			Component: component,
+			recorder:  recorder,
+			prefix:    prefix,
+		}
+	}
+}
+
+func TestWithMiddleware(t *testing.T) {
+	t.Run("order", func(t *testing.T) {
+		rec := supervisortest.NewRecorder()
+		ctx := supervisor.WithMiddleware(context.Background(), recordingMiddleware(rec, "a"))
+		ctx = supervisor.WithMiddleware(ctx, recordingMiddleware(rec, "b"))
+		c1 := supervisortest.NewComponent("1", rec)
+		sv := supervisor.NewChain(ctx, c1)
+		assert.NoError(t, sv.Open())
+		assert.NoError(t, sv.Close())
+		assert.NoError(t, sv.Wait())
+		rec.Assert(t, "a-1", "b-1", "1-open", "1-close", "1-wait")
+	})
+	t.Run("path", func(t *testing.T) {
+		rec := supervisortest.NewRecorder()
+		ctx := supervisor.WithMiddleware(context.Background(), recordingMiddleware(rec, "m"))
+		ctx = supervisor.WithName(ctx, "root")
+		inner := supervisor.NewGroup(supervisor.WithName(ctx, "inner"),
+			supervisortest.NewComponent("1", nil),
+		)
+		sv := supervisor.NewChain(ctx,
+			inner,
+			supervisortest.NewComponent("", nil),
+		)
+		assert.Equal(t, "root", sv.Name())
+		assert.Equal(t, "inner", inner.Name())
+		assert.NoError(t, sv.Open())
+		assert.NoError(t, sv.Close())
+		assert.NoError(t, sv.Wait())
+		rec.AssertOrder(t,
+			[]string{"m-root/inner"},
+			[]string{"m-root/inner/1"},
+			[]string{"m-root/1"},
+		)
+	})
+	t.Run("no middlewares", func(t *testing.T) {
+		c1 := supervisortest.NewComponent("1", nil)
+		assert.Equal(t, "1", supervisor.PathOf(c1))
+		sv := supervisor.NewGroup(supervisor.WithName(context.Background(), "root"), c1)
+		assert.Equal(t, "root", sv.Name())
+		assert.Equal(t, "", supervisor.NameOf(supervisor.NewGroup(context.Background())))
+	})
+}
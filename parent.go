@@ -0,0 +1,72 @@
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+)
+
+var (
+	// ErrParentDied is returned by ParentWatchdog if parent process is died
+	ErrParentDied = errors.New("parent process died")
+)
+
+/*
+ParentWatchdog closes supervisor tree when parent process dies. Use
+ParentWatchdog as watchdog in Group. ParentWatchdog polls parent process ID
+and fails with ErrParentDied when process is reparented.
+
+ParentWatchdog polls parent process ID instead of PR_SET_PDEATHSIG because
+later is triggered by exit of parent thread which started process.
+*/
+type ParentWatchdog struct {
+	*Control
+	parent   int
+	interval time.Duration
+	clock    Clock
+}
+
+// NewParentWatchdog returns new ParentWatchdog which polls parent process ID
+// with given interval. Default interval is one second. Parent process is
+// determined on construction. ParentWatchdog uses Clock and name from given
+// Context.
+func NewParentWatchdog(ctx context.Context, interval time.Duration) (w *ParentWatchdog) {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	return &ParentWatchdog{
+		Control:  NewControl(ctx),
+		parent:   os.Getppid(),
+		interval: interval,
+		clock:    ClockFrom(ctx),
+	}
+}
+
+// Open starts polling. Open fails with ErrParentDied if parent process is
+// already died. Repeated calls return the result of the first call.
+func (w *ParentWatchdog) Open() (err error) {
+	return w.openWith(w.open)
+}
+
+func (w *ParentWatchdog) open() (err error) {
+	if os.Getppid() != w.parent {
+		w.Fail(ErrParentDied)
+		return ErrParentDied
+	}
+	w.Go(func(ctx context.Context) (err error) {
+		ticker := w.clock.NewTicker(w.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C():
+				if os.Getppid() != w.parent {
+					return ErrParentDied
+				}
+			}
+		}
+	})
+	return nil
+}
@@ -0,0 +1,75 @@
+//go:build !windows
+
+package supervisor_test
+
+import (
+	"context"
+	"github.com/akaspin/supervisor"
+	"github.com/akaspin/supervisor/supervisortest"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const parentWatchdogHelperEnv = "SUPERVISOR_PARENT_WATCHDOG_HELPER"
+
+// TestParentWatchdogHelper is run in orphaned process by TestParentWatchdog
+func TestParentWatchdogHelper(t *testing.T) {
+	out := os.Getenv(parentWatchdogHelperEnv)
+	if out == "" {
+		t.Skip("helper process")
+	}
+	w := supervisor.NewParentWatchdog(context.Background(), time.Millisecond*10)
+	sv := supervisor.NewGroup(context.Background(), w)
+	if err := sv.Open(); err != nil {
+		os.Exit(1)
+	}
+	sv.Wait()
+	os.WriteFile(out, []byte(w.Wait().Error()), 0644)
+}
+
+func TestParentWatchdog(t *testing.T) {
+	dir, err := os.MkdirTemp("", "")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	out := filepath.Join(dir, "out")
+
+	// shell starts helper in background and exits
+	cmd := exec.Command("sh", "-c", `"$0" -test.run=TestParentWatchdogHelper >/dev/null 2>&1 & sleep 0.2`, os.Args[0])
+	cmd.Env = append(os.Environ(), parentWatchdogHelperEnv+"="+out)
+	assert.NoError(t, cmd.Run())
+
+	var data []byte
+	deadline := time.Now().Add(time.Second * 5)
+	for time.Now().Before(deadline) {
+		if data, err = os.ReadFile(out); err == nil && len(data) > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	assert.Equal(t, supervisor.ErrParentDied.Error(), string(data))
+}
+
+func TestParentWatchdog_Close(t *testing.T) {
+	w := supervisor.NewParentWatchdog(context.Background(), time.Millisecond)
+	assert.NoError(t, w.Open())
+	time.Sleep(time.Millisecond * 10)
+	assert.NoError(t, w.Close())
+	assert.NoError(t, w.Wait())
+}
+
+func TestParentWatchdog_DefaultInterval(t *testing.T) {
+	w := supervisor.NewParentWatchdog(context.Background(), 0)
+	assert.NoError(t, w.Open())
+	assert.NoError(t, w.Close())
+	assert.NoError(t, w.Wait())
+}
+
+func TestParentWatchdog_Conformance(t *testing.T) {
+	supervisortest.Conformance(t, func() supervisor.Component {
+		return supervisor.NewParentWatchdog(context.Background(), time.Millisecond*10)
+	})
+}
@@ -0,0 +1,223 @@
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNotReady is returned by WaitFor Open() if probe is not succeeded
+	// before timeout
+	ErrNotReady = errors.New("not ready")
+)
+
+// Probe checks readiness of Component. Probe should return nil if Component
+// is ready.
+type Probe func(ctx context.Context) (err error)
+
+// TCPProbe returns Probe which connects to given TCP address
+func TCPProbe(addr string) Probe {
+	return dialProbe("tcp", addr)
+}
+
+// UnixProbe returns Probe which connects to unix socket with given path
+func UnixProbe(path string) Probe {
+	return dialProbe("unix", path)
+}
+
+func dialProbe(network, addr string) Probe {
+	return func(ctx context.Context) (err error) {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, network, addr)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+}
+
+// FileProbe returns Probe which checks existence of file with given path
+func FileProbe(path string) Probe {
+	return func(ctx context.Context) (err error) {
+		_, err = os.Stat(path)
+		return err
+	}
+}
+
+// HTTPProbe returns Probe which requests given URL with GET method and
+// expects 2xx status code
+func HTTPProbe(url string) Probe {
+	return func(ctx context.Context) (err error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return errors.New("unexpected status " + strconv.Itoa(resp.StatusCode))
+		}
+		return nil
+	}
+}
+
+// WaitForConfig configures WaitFor
+type WaitForConfig struct {
+
+	// Timeout is maximum time to wait for successful probe. Default is 30
+	// seconds.
+	Timeout time.Duration
+
+	// Interval is interval between probes. Each probe is limited by
+	// Interval. Default is 100 milliseconds.
+	Interval time.Duration
+
+	// ExitTimeout is maximum time to wait for exit of wrapped Component
+	// after failed probing. Default is 10 seconds.
+	ExitTimeout time.Duration
+}
+
+/*
+WaitFor blocks Open() of wrapped Component until Probe is succeeded. Use
+WaitFor with components which return from Open() before they are ready. Chain
+opens next component only after WaitFor Open() is returned.
+
+If Probe is not succeeded before timeout WaitFor closes wrapped Component,
+waits for its exit at most ExitTimeout and returns error which wraps
+ErrNotReady and last Probe error. Chain, Group and Timeout abort probing if
+they are closed while WaitFor is opening.
+*/
+type WaitFor struct {
+	Component
+	ctx    context.Context
+	cancel context.CancelFunc
+	probe  Probe
+	config WaitForConfig
+	clock  Clock
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// NewWaitFor returns new WaitFor. WaitFor uses Clock from given Context.
+func NewWaitFor(ctx context.Context, component Component, probe Probe, config WaitForConfig) (w *WaitFor) {
+	if config.Timeout <= 0 {
+		config.Timeout = time.Second * 30
+	}
+	if config.Interval <= 0 {
+		config.Interval = time.Millisecond * 100
+	}
+	if config.ExitTimeout <= 0 {
+		config.ExitTimeout = time.Second * 10
+	}
+	w = &WaitFor{
+		Component: component,
+		probe:     probe,
+		config:    config,
+		clock:     ClockFrom(ctx),
+	}
+	w.ctx, w.cancel = context.WithCancel(ctx)
+	return w
+}
+
+// Unwrap returns wrapped Component
+func (w *WaitFor) Unwrap() Component {
+	return w.Component
+}
+
+// Open opens wrapped Component and blocks until Probe is succeeded
+func (w *WaitFor) Open() (err error) {
+	if err = w.Component.Open(); err != nil {
+		return err
+	}
+	if err = w.wait(); err != nil {
+		w.closeComponent()
+		w.waitExit()
+		return err
+	}
+	return nil
+}
+
+// Close aborts probing and closes wrapped Component
+func (w *WaitFor) Close() (err error) {
+	w.cancel()
+	return w.closeComponent()
+}
+
+// interruptOpen aborts probing
+func (w *WaitFor) interruptOpen() {
+	w.cancel()
+}
+
+// wait probes wrapped Component until success, timeout or close
+func (w *WaitFor) wait() (err error) {
+	timer := w.clock.NewTimer(w.config.Timeout)
+	defer timer.Stop()
+	interval := w.clock.NewTimer(w.config.Interval)
+	interval.Stop()
+	defer interval.Stop()
+	for {
+		ctx, cancel := context.WithTimeout(w.ctx, w.config.Interval)
+		err = w.probe(ctx)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		interval.Reset(w.config.Interval)
+		select {
+		case <-w.ctx.Done():
+			return ErrPrematurelyClosed
+		case <-timer.C():
+			return &notReadyError{err: err}
+		case <-interval.C():
+		}
+	}
+}
+
+// waitExit waits for exit of wrapped Component at most ExitTimeout
+func (w *WaitFor) waitExit() {
+	exitChan := make(chan struct{})
+	go func() {
+		defer close(exitChan)
+		w.Component.Wait()
+	}()
+	timer := w.clock.NewTimer(w.config.ExitTimeout)
+	defer timer.Stop()
+	select {
+	case <-exitChan:
+	case <-timer.C():
+	}
+}
+
+func (w *WaitFor) closeComponent() (err error) {
+	w.closeOnce.Do(func() {
+		w.closeErr = w.Component.Close()
+	})
+	return w.closeErr
+}
+
+// notReadyError wraps ErrNotReady and last Probe error
+type notReadyError struct {
+	err error
+}
+
+func (e *notReadyError) Error() string {
+	return ErrNotReady.Error() + ": " + e.err.Error()
+}
+
+func (e *notReadyError) Is(target error) bool {
+	return target == ErrNotReady
+}
+
+func (e *notReadyError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,161 @@
+package supervisor_test
+
+import (
+	"context"
+	"errors"
+	"github.com/akaspin/supervisor"
+	"github.com/akaspin/supervisor/supervisortest"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var probeConfig = supervisor.WaitForConfig{
+	Timeout:  time.Second * 5,
+	Interval: time.Millisecond * 10,
+}
+
+func TestWaitFor(t *testing.T) {
+	t.Run("tcp", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.NoError(t, err)
+		addr := ln.Addr().String()
+		assert.NoError(t, ln.Close())
+
+		rec := supervisortest.NewRecorder()
+		c1 := supervisortest.NewComponent("1", rec)
+		c2 := supervisortest.NewComponent("2", rec)
+		sv := supervisor.NewChain(context.Background(),
+			supervisor.NewWaitFor(context.Background(), c1, supervisor.TCPProbe(addr), probeConfig),
+			c2,
+		)
+		go func() {
+			time.Sleep(time.Millisecond * 50)
+			rec.Record("listen")
+			ln, err := net.Listen("tcp", addr)
+			if assert.NoError(t, err) {
+				defer ln.Close()
+				conn, err := ln.Accept()
+				if err == nil {
+					conn.Close()
+				}
+			}
+		}()
+		assert.NoError(t, sv.Open())
+		assert.NoError(t, sv.Close())
+		assert.NoError(t, sv.Wait())
+		rec.AssertOrder(t,
+			[]string{"1-open"},
+			[]string{"listen"},
+			[]string{"2-open"},
+			[]string{"2-close"},
+			[]string{"2-wait"},
+			[]string{"1-close"},
+			[]string{"1-wait"},
+		)
+	})
+	t.Run("file", func(t *testing.T) {
+		dir, err := os.MkdirTemp("", "")
+		assert.NoError(t, err)
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "ready")
+		w := supervisor.NewWaitFor(context.Background(), supervisortest.NewComponent("1", nil),
+			supervisor.FileProbe(path), probeConfig)
+		time.AfterFunc(time.Millisecond*50, func() {
+			os.WriteFile(path, nil, 0644)
+		})
+		assert.NoError(t, w.Open())
+		assert.Equal(t, "1", supervisor.NameOf(w))
+		assert.NoError(t, w.Close())
+		assert.NoError(t, w.Wait())
+	})
+	t.Run("http", func(t *testing.T) {
+		var requests int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&requests, 1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		defer srv.Close()
+		w := supervisor.NewWaitFor(context.Background(), supervisortest.NewComponent("1", nil),
+			supervisor.HTTPProbe(srv.URL), probeConfig)
+		assert.NoError(t, w.Open())
+		assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+		assert.NoError(t, w.Close())
+		assert.NoError(t, w.Wait())
+	})
+	t.Run("timeout", func(t *testing.T) {
+		clock := supervisortest.NewClock(time.Now())
+		c1 := supervisortest.NewComponent("1", nil)
+		w := supervisor.NewWaitFor(supervisor.WithClock(context.Background(), clock), c1,
+			func(ctx context.Context) error {
+				return errors.New("bang")
+			}, supervisor.WaitForConfig{
+				Timeout:  time.Second,
+				Interval: time.Millisecond * 100,
+			})
+		errChan := make(chan error, 1)
+		go func() {
+			errChan <- w.Open()
+		}()
+		for i := 0; i < 10; i++ {
+			clock.BlockUntil(2)
+			clock.Advance(time.Millisecond * 100)
+		}
+		err := <-errChan
+		assert.True(t, errors.Is(err, supervisor.ErrNotReady))
+		assert.EqualError(t, err, "not ready: bang")
+		assert.Equal(t, 1, c1.Calls(supervisor.PhaseClose))
+		assert.Equal(t, 1, c1.Calls(supervisor.PhaseWait))
+	})
+	t.Run("close", func(t *testing.T) {
+		c1 := supervisortest.NewComponent("1", nil)
+		w := supervisor.NewWaitFor(context.Background(), c1,
+			supervisor.FileProbe("/nonexistent"), probeConfig)
+		time.AfterFunc(time.Millisecond*50, func() {
+			w.Close()
+		})
+		assert.Equal(t, supervisor.ErrPrematurelyClosed, w.Open())
+		assert.NoError(t, w.Wait())
+		assert.Equal(t, 1, c1.Calls(supervisor.PhaseClose))
+	})
+	t.Run("chain close", func(t *testing.T) {
+		c1 := supervisortest.NewComponent("1", nil)
+		sv := supervisor.NewChain(context.Background(),
+			supervisor.NewTimeout(context.Background(), time.Minute,
+				supervisor.NewWaitFor(context.Background(), c1,
+					supervisor.FileProbe("/nonexistent"), supervisor.WaitForConfig{
+						Timeout:  time.Minute,
+						Interval: time.Millisecond * 10,
+					}),
+			),
+		)
+		time.AfterFunc(time.Millisecond*50, func() {
+			sv.Close()
+		})
+		assert.Equal(t, supervisor.ErrPrematurelyClosed, sv.Open())
+		assert.NoError(t, sv.Wait())
+		assert.Equal(t, 1, c1.Calls(supervisor.PhaseClose))
+		assert.Equal(t, 1, c1.Calls(supervisor.PhaseWait))
+	})
+	t.Run("exit timeout", func(t *testing.T) {
+		block := make(chan struct{})
+		defer close(block)
+		c1 := supervisortest.NewComponent("1", nil).
+			On(supervisor.PhaseWait, supervisortest.Script{Block: block})
+		w := supervisor.NewWaitFor(context.Background(), c1,
+			supervisor.FileProbe("/nonexistent"), supervisor.WaitForConfig{
+				Timeout:     time.Millisecond * 50,
+				Interval:    time.Millisecond * 10,
+				ExitTimeout: time.Millisecond * 50,
+			})
+		assert.True(t, errors.Is(w.Open(), supervisor.ErrNotReady))
+		assert.Equal(t, 1, c1.Calls(supervisor.PhaseClose))
+	})
+}
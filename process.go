@@ -0,0 +1,372 @@
+//go:build !windows
+
+package supervisor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ProcessConfig configures Process
+type ProcessConfig struct {
+
+	// StopSignal is signal sent to process group on Close(). Default is
+	// SIGTERM.
+	StopSignal syscall.Signal
+
+	// Output receives lines of process stdout and stderr
+	Output io.Writer
+
+	// Logger logs lines of process stdout and stderr with "stream"
+	// attribute.
+	Logger *slog.Logger
+
+	// MaxLine is maximum length of line. Longer lines are split. Default is
+	// 64 KiB.
+	MaxLine int
+
+	// WaitDelay is maximum time to wait for output after process exit.
+	// Default is one second. See exec.Cmd.WaitDelay.
+	WaitDelay time.Duration
+}
+
+// ProcessError is returned by Process Wait() if process exited with non-zero
+// code or was killed by signal.
+type ProcessError struct {
+
+	// Name is Process name
+	Name string
+
+	// Code is exit code. Code is -1 if process was killed by signal.
+	Code int
+
+	// Signal is signal which killed process if any
+	Signal syscall.Signal
+}
+
+func (e *ProcessError) Error() string {
+	if e.Code < 0 {
+		return "process " + e.Name + " killed by signal: " + e.Signal.String()
+	}
+	return "process " + e.Name + " exited with code " + strconv.Itoa(e.Code)
+}
+
+/*
+Process supervises external process. Open() starts process in own process
+group. Close() sends StopSignal to process group. Wait() waits for process
+exit and returns *ProcessError if process exited with non-zero code. Process
+terminated by StopSignal after Close() is considered exited normally. After
+process exit remaining processes in its group receive StopSignal.
+
+Process implements Killer. Use Process with Timeout to kill process group
+which ignores StopSignal.
+*/
+type Process struct {
+	*lifecycle
+	name   string
+	ctx    context.Context
+	cancel context.CancelFunc
+	cmd    *exec.Cmd
+	config ProcessConfig
+
+	openChan chan struct{} // closed after start or premature close
+	openErr  compositeError
+
+	stopChan chan struct{} // closed after stop signal is sent
+	stopErr  compositeError
+
+	waitErr compositeError
+}
+
+// NewProcess returns new Process which runs given command. Process uses name
+// from given Context. If Context has no name base name of command path is
+// used. See WithName.
+func NewProcess(ctx context.Context, cmd *exec.Cmd, config ProcessConfig) (p *Process) {
+	if config.StopSignal == 0 {
+		config.StopSignal = syscall.SIGTERM
+	}
+	if config.MaxLine <= 0 {
+		config.MaxLine = 64 * 1024
+	}
+	if config.WaitDelay <= 0 {
+		config.WaitDelay = time.Second
+	}
+	name := baseName(PathFrom(ctx))
+	if name == "" {
+		name = filepath.Base(cmd.Path)
+	}
+	p = &Process{
+		lifecycle: newLifecycle(ClockFrom(ctx)),
+		name:      name,
+		cmd:       cmd,
+		config:    config,
+		openChan:  make(chan struct{}),
+		stopChan:  make(chan struct{}),
+	}
+	p.ctx, p.cancel = context.WithCancel(ctx)
+	context.AfterFunc(p.ctx, p.stop)
+	return p
+}
+
+// Name returns Process name
+func (p *Process) Name() string {
+	return p.name
+}
+
+// Pid returns process ID. Pid returns zero if process is not started.
+func (p *Process) Pid() (pid int) {
+	select {
+	case <-p.openChan:
+	default:
+		return 0
+	}
+	if p.cmd.Process == nil {
+		return 0
+	}
+	return p.cmd.Process.Pid
+}
+
+// Open starts process. Open returns ErrPrematurelyClosed if Process is
+// closed before open.
+func (p *Process) Open() (err error) {
+	if p.ctx.Err() != nil || !p.transit(StateOpening, nil, StateNew) {
+		<-p.openChan
+		if p.isPrematurelyClosed() {
+			return ErrPrematurelyClosed
+		}
+		return p.openErr.get()
+	}
+	defer close(p.openChan)
+	if p.cmd.SysProcAttr == nil {
+		p.cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	p.cmd.SysProcAttr.Setpgid = true
+	p.cmd.WaitDelay = p.config.WaitDelay
+	var outputs []*lineWriter
+	if p.config.Output != nil || p.config.Logger != nil {
+		var mu sync.Mutex
+		stdout := &lineWriter{process: p, stream: "stdout", mu: &mu}
+		stderr := &lineWriter{process: p, stream: "stderr", mu: &mu}
+		p.cmd.Stdout, p.cmd.Stderr = stdout, stderr
+		outputs = append(outputs, stdout, stderr)
+	}
+	if err = p.cmd.Start(); err != nil {
+		p.openErr.set(err)
+		p.closing()
+		p.exit(err)
+		return err
+	}
+	p.transit(StateOpen, nil, StateOpening)
+	pid := p.cmd.Process.Pid
+	managed.add(pid)
+	go func() {
+		err := p.cmd.Wait()
+		managed.remove(pid)
+		for _, output := range outputs {
+			output.flush()
+		}
+		err = p.exitError(err, p.ctx.Err() != nil)
+		p.cancel()
+		<-p.stopChan
+		p.waitErr.set(err)
+		p.closing()
+		p.exit(err)
+	}()
+	return nil
+}
+
+// Close sends StopSignal to process group
+func (p *Process) Close() (err error) {
+	p.cancel()
+	<-p.stopChan
+	return p.stopErr.get()
+}
+
+// Wait waits for process exit
+func (p *Process) Wait() (err error) {
+	<-p.doneChan()
+	return p.waitErr.get()
+}
+
+// Kill kills process group with SIGKILL
+func (p *Process) Kill() (err error) {
+	return p.signal(syscall.SIGKILL)
+}
+
+// stop is called then Process context is done
+func (p *Process) stop() {
+	defer close(p.stopChan)
+	if p.transit(StateClosed, nil, StateNew) {
+		close(p.openChan)
+		return
+	}
+	<-p.openChan
+	if p.openErr.get() != nil {
+		return
+	}
+	p.closing()
+	if err := p.signal(p.config.StopSignal); err != nil {
+		p.stopErr.set(err)
+	}
+}
+
+// signal sends given signal to process group if process is running
+func (p *Process) signal(sig syscall.Signal) (err error) {
+	if p.Pid() == 0 || p.State().IsTerminal() {
+		return nil
+	}
+	if err = syscall.Kill(-p.cmd.Process.Pid, sig); errors.Is(err, syscall.ESRCH) {
+		return nil
+	}
+	return err
+}
+
+// exitError converts error returned by exec.Cmd Wait(). Termination by
+// StopSignal is not error if process is stopped.
+func (p *Process) exitError(err error, stopped bool) error {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return err
+	}
+	status, ok := exitErr.Sys().(syscall.WaitStatus)
+	if !ok {
+		return err
+	}
+	res := &ProcessError{
+		Name: p.name,
+		Code: status.ExitStatus(),
+	}
+	if status.Signaled() {
+		res.Signal = status.Signal()
+		if stopped && res.Signal == p.config.StopSignal {
+			return nil
+		}
+	}
+	return res
+}
+
+// managed holds IDs of running processes started by Process and IDs of
+// their process groups
+var managed = &managedPids{
+	pids:   map[int]struct{}{},
+	groups: map[int]struct{}{},
+}
+
+type managedPids struct {
+	mu     sync.Mutex
+	pids   map[int]struct{}
+	groups map[int]struct{} // process groups which may contain orphans
+}
+
+func (m *managedPids) add(pid int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pids[pid] = struct{}{}
+	m.groups[pid] = struct{}{}
+}
+
+func (m *managedPids) remove(pid int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.pids, pid)
+}
+
+func (m *managedPids) has(pid int) (ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok = m.pids[pid]
+	return ok
+}
+
+// isOrphan returns true if process with given ID and process group is
+// orphaned member of managed process group
+func (m *managedPids) isOrphan(pid, pgid int) (ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, running := m.pids[pid]
+	_, ok = m.groups[pgid]
+	return ok && !running
+}
+
+// prune removes process groups which are not in given set and have no
+// running leader
+func (m *managedPids) prune(alive map[int]struct{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for pgid := range m.groups {
+		_, running := m.pids[pgid]
+		if _, ok := alive[pgid]; !ok && !running {
+			delete(m.groups, pgid)
+		}
+	}
+}
+
+func (m *managedPids) list() (pids []int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for pid := range m.pids {
+		pids = append(pids, pid)
+	}
+	return pids
+}
+
+// lineWriter splits process output into lines
+type lineWriter struct {
+	process *Process
+	stream  string
+	mu      *sync.Mutex // shared by stdout and stderr
+	buf     []byte
+}
+
+func (w *lineWriter) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(w.buf, p...)
+	maxLine := w.process.config.MaxLine
+	for {
+		if i := bytes.IndexByte(w.buf, '\n'); i >= 0 && i <= maxLine {
+			w.line(w.buf[:i])
+			w.buf = w.buf[i+1:]
+			continue
+		}
+		if len(w.buf) < maxLine {
+			break
+		}
+		// split too long line
+		w.line(w.buf[:maxLine])
+		w.buf = w.buf[maxLine:]
+	}
+	return len(p), nil
+}
+
+// flush forwards incomplete last line
+func (w *lineWriter) flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.buf) > 0 {
+		w.line(w.buf)
+		w.buf = nil
+	}
+}
+
+func (w *lineWriter) line(line []byte) {
+	config := w.process.config
+	if config.Output != nil {
+		config.Output.Write(append(append([]byte(nil), line...), '\n'))
+	}
+	if config.Logger != nil {
+		config.Logger.LogAttrs(context.Background(), slog.LevelInfo, string(line),
+			slog.String(LogComponent, w.process.name),
+			slog.String("stream", w.stream),
+		)
+	}
+}
@@ -0,0 +1,104 @@
+//go:build !windows
+
+package supervisor_test
+
+import (
+	"context"
+	"errors"
+	"github.com/akaspin/supervisor"
+	"github.com/akaspin/supervisor/supervisortest"
+	"github.com/stretchr/testify/assert"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestProcess(t *testing.T) {
+	t.Run("output", func(t *testing.T) {
+		buf := &logBuffer{}
+		p := supervisor.NewProcess(context.Background(),
+			exec.Command("sh", "-c", "echo 1; echo 2 >&2; printf 3; exec sleep 10"),
+			supervisor.ProcessConfig{
+				Output: buf,
+			})
+		assert.Equal(t, "sh", p.Name())
+		assert.NoError(t, p.Open())
+		assert.NotZero(t, p.Pid())
+		time.Sleep(time.Millisecond * 100)
+		assert.NoError(t, p.Close())
+		assert.NoError(t, p.Wait())
+		assert.Equal(t, supervisor.StateClosed, p.State())
+		lines := buf.buf.String()
+		assert.Contains(t, lines, "1\n")
+		assert.Contains(t, lines, "2\n")
+		assert.Contains(t, lines, "3\n")
+	})
+	t.Run("logger", func(t *testing.T) {
+		buf := &logBuffer{}
+		p := supervisor.NewProcess(supervisor.WithName(context.Background(), "echo"),
+			exec.Command("sh", "-c", "echo hello"),
+			supervisor.ProcessConfig{
+				Logger: newTestLogger(buf),
+			})
+		assert.NoError(t, p.Open())
+		assert.NoError(t, p.Wait())
+		assert.Equal(t, []string{
+			`level=INFO msg=hello component=echo stream=stdout`,
+		}, buf.lines("hello"))
+	})
+	t.Run("long line", func(t *testing.T) {
+		buf := &logBuffer{}
+		p := supervisor.NewProcess(context.Background(),
+			exec.Command("sh", "-c", "printf 1234567; echo 89"),
+			supervisor.ProcessConfig{
+				Output:  buf,
+				MaxLine: 4,
+			})
+		assert.NoError(t, p.Open())
+		assert.NoError(t, p.Wait())
+		assert.Equal(t, "1234\n5678\n9\n", buf.buf.String())
+	})
+	t.Run("exit code", func(t *testing.T) {
+		p := supervisor.NewProcess(context.Background(),
+			exec.Command("sh", "-c", "exit 3"), supervisor.ProcessConfig{})
+		assert.NoError(t, p.Open())
+		err := p.Wait()
+		var processErr *supervisor.ProcessError
+		if assert.True(t, errors.As(err, &processErr)) {
+			assert.Equal(t, 3, processErr.Code)
+		}
+		assert.EqualError(t, err, "process sh exited with code 3")
+		assert.Equal(t, supervisor.StateFailed, p.State())
+	})
+	t.Run("start error", func(t *testing.T) {
+		p := supervisor.NewProcess(context.Background(),
+			exec.Command("/nonexistent"), supervisor.ProcessConfig{})
+		assert.Error(t, p.Open())
+		assert.NoError(t, p.Close())
+		assert.NoError(t, p.Wait())
+	})
+	t.Run("timeout", func(t *testing.T) {
+		p := supervisor.NewProcess(context.Background(),
+			exec.Command("sh", "-c", `trap "" TERM; sleep 10 & wait`),
+			supervisor.ProcessConfig{})
+		to := supervisor.NewTimeout(context.Background(), time.Millisecond*100, p)
+		assert.NoError(t, to.Open())
+		time.Sleep(time.Millisecond * 100)
+		assert.NoError(t, to.Close())
+		assert.Equal(t, supervisor.ErrTimeout, to.Wait())
+
+		err := p.Wait()
+		var processErr *supervisor.ProcessError
+		if assert.True(t, errors.As(err, &processErr)) {
+			assert.Equal(t, syscall.SIGKILL, processErr.Signal)
+		}
+	})
+}
+
+func TestProcess_Conformance(t *testing.T) {
+	supervisortest.Conformance(t, func() supervisor.Component {
+		return supervisor.NewProcess(context.Background(),
+			exec.Command("sleep", "10"), supervisor.ProcessConfig{})
+	})
+}
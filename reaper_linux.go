@@ -0,0 +1,249 @@
+package supervisor
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const prSetChildSubreaper = 36
+
+// ReaperConfig configures Reaper
+type ReaperConfig struct {
+
+	// Interval is interval between scans for zombies in addition to scans on
+	// SIGCHLD. Default is one second.
+	Interval time.Duration
+
+	// Grace is minimal time of zombie existence before it is reaped.
+	// Default is one second.
+	Grace time.Duration
+
+	// Forward is list of signals forwarded to process groups of running
+	// Processes. Note that Go program is not terminated by forwarded
+	// signals.
+	Forward []os.Signal
+
+	// OnReap is called for each reaped process
+	OnReap func(pid int, status syscall.WaitStatus)
+}
+
+/*
+Reaper reaps orphaned processes. Open() makes current process child
+subreaper (PR_SET_CHILD_SUBREAPER) so orphaned descendants are reparented to
+it instead of init. Use Reaper when supervisor runs as PID 1 in container or
+supervises processes which spawn daemons.
+
+Reaper reaps only known orphans which are zombies for Grace period. Orphan
+is known if it belongs to process group of Process or if it was seen by
+Reaper as descendant before it was reparented. Reaper never reaps processes
+started by Process and other children of current process.
+*/
+type Reaper struct {
+	*Control
+	config      ReaperConfig
+	clock       Clock
+	seen        map[int]time.Time // zombies by first seen time
+	descendants map[int]struct{}  // descendants which are not children
+}
+
+// NewReaper returns new Reaper. Reaper uses Clock and name from given
+// Context.
+func NewReaper(ctx context.Context, config ReaperConfig) (r *Reaper) {
+	if config.Interval <= 0 {
+		config.Interval = time.Second
+	}
+	if config.Grace <= 0 {
+		config.Grace = time.Second
+	}
+	return &Reaper{
+		Control: NewControl(ctx),
+		config:  config,
+		clock:   ClockFrom(ctx),
+		seen:    map[int]time.Time{},
+
+		descendants: map[int]struct{}{},
+	}
+}
+
+// Open makes current process child subreaper and starts reaping. Repeated
+// calls return the result of the first call.
+func (r *Reaper) Open() (err error) {
+	return r.openWith(r.open)
+}
+
+func (r *Reaper) open() (err error) {
+	if err = setChildSubreaper(true); err != nil {
+		r.Fail(err)
+		return err
+	}
+	r.OnClose(func() error {
+		return setChildSubreaper(false)
+	})
+	sigChan := make(chan os.Signal, 16)
+	signal.Notify(sigChan, append([]os.Signal{syscall.SIGCHLD}, r.config.Forward...)...)
+	r.OnClose(func() error {
+		signal.Stop(sigChan)
+		return nil
+	})
+	r.Go(func(ctx context.Context) (err error) {
+		ticker := r.clock.NewTicker(r.config.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case sig := <-sigChan:
+				if sig != syscall.SIGCHLD {
+					forward(sig)
+					continue
+				}
+			case <-ticker.C():
+			}
+			r.reap()
+		}
+	})
+	return nil
+}
+
+// reap reaps zombie orphans which exist longer than Grace period
+func (r *Reaper) reap() {
+	now := r.clock.Now()
+	self := os.Getpid()
+	procs := processes()
+	parents := map[int]int{}
+	groups := map[int]struct{}{}
+	for _, p := range procs {
+		parents[p.pid] = p.ppid
+		groups[p.pgid] = struct{}{}
+	}
+	managed.prune(groups)
+	zombies := map[int]struct{}{}
+	descendants := map[int]struct{}{}
+	for _, p := range procs {
+		if p.ppid != self {
+			if isDescendant(parents, p.ppid, self) {
+				descendants[p.pid] = struct{}{}
+			}
+			continue
+		}
+		if _, known := r.descendants[p.pid]; known {
+			descendants[p.pid] = struct{}{}
+		} else if !managed.isOrphan(p.pid, p.pgid) {
+			continue
+		}
+		if !p.zombie {
+			continue
+		}
+		pid := p.pid
+		zombies[pid] = struct{}{}
+		first, ok := r.seen[pid]
+		if !ok {
+			r.seen[pid] = now
+			continue
+		}
+		if now.Sub(first) < r.config.Grace {
+			continue
+		}
+		var status syscall.WaitStatus
+		if reaped, err := syscall.Wait4(pid, &status, syscall.WNOHANG, nil); err == nil && reaped == pid {
+			delete(r.seen, pid)
+			if r.config.OnReap != nil {
+				r.config.OnReap(pid, status)
+			}
+		}
+	}
+	for pid := range r.seen {
+		if _, ok := zombies[pid]; !ok {
+			delete(r.seen, pid) // reaped by owner
+		}
+	}
+	r.descendants = descendants
+}
+
+// isDescendant returns true if process with given ID is given ancestor or
+// its descendant
+func isDescendant(parents map[int]int, pid, ancestor int) (ok bool) {
+	for i := 0; i <= len(parents); i++ {
+		if pid == ancestor {
+			return true
+		}
+		var found bool
+		if pid, found = parents[pid]; !found || pid == 0 {
+			return false
+		}
+	}
+	return false
+}
+
+// forward sends given signal to process groups of running Processes
+func forward(sig os.Signal) {
+	sysSig, ok := sig.(syscall.Signal)
+	if !ok {
+		return
+	}
+	for _, pid := range managed.list() {
+		syscall.Kill(-pid, sysSig)
+	}
+}
+
+func setChildSubreaper(on bool) (err error) {
+	var arg uintptr
+	if on {
+		arg = 1
+	}
+	if _, _, errno := syscall.RawSyscall(syscall.SYS_PRCTL, prSetChildSubreaper, arg, 0); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// procStat describes process from /proc
+type procStat struct {
+	pid    int
+	ppid   int
+	pgid   int
+	zombie bool
+}
+
+// processes returns all visible processes
+func processes() (procs []procStat) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil
+	}
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		stat, err := os.ReadFile("/proc/" + entry.Name() + "/stat")
+		if err != nil {
+			continue
+		}
+		// pid (comm) state ppid pgrp ...
+		fields := strings.Fields(string(stat[strings.LastIndexByte(string(stat), ')')+1:]))
+		if len(fields) < 3 {
+			continue
+		}
+		ppid, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+		pgid, err := strconv.Atoi(fields[2])
+		if err != nil {
+			continue
+		}
+		procs = append(procs, procStat{
+			pid:    pid,
+			ppid:   ppid,
+			pgid:   pgid,
+			zombie: fields[0] == "Z",
+		})
+	}
+	return procs
+}
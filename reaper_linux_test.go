@@ -0,0 +1,78 @@
+package supervisor_test
+
+import (
+	"context"
+	"errors"
+	"github.com/akaspin/supervisor"
+	"github.com/akaspin/supervisor/supervisortest"
+	"github.com/stretchr/testify/assert"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestReaper(t *testing.T) {
+	var mu sync.Mutex
+	var reaped []int
+	r := supervisor.NewReaper(context.Background(), supervisor.ReaperConfig{
+		Interval: time.Millisecond * 20,
+		Grace:    time.Millisecond * 50,
+		OnReap: func(pid int, status syscall.WaitStatus) {
+			mu.Lock()
+			defer mu.Unlock()
+			reaped = append(reaped, pid)
+		},
+	})
+	assert.NoError(t, r.Open())
+
+	// managed process exit status is not stolen
+	p := supervisor.NewProcess(context.Background(),
+		exec.Command("sh", "-c", "sleep 0.3; exit 7"), supervisor.ProcessConfig{})
+	assert.NoError(t, p.Open())
+
+	// orphan from process group of Process is reparented to subreaper
+	buf := &logBuffer{}
+	leader := supervisor.NewProcess(context.Background(),
+		exec.Command("sh", "-c", "sleep 0.1 >/dev/null 2>&1 & echo $!"),
+		supervisor.ProcessConfig{Output: buf})
+	assert.NoError(t, leader.Open())
+	assert.NoError(t, leader.Wait())
+	orphan, err := strconv.Atoi(strings.TrimSpace(buf.buf.String()))
+	assert.NoError(t, err)
+
+	// exit status of unmanaged child is not stolen
+	child := exec.Command("true")
+	assert.NoError(t, child.Start())
+
+	var processErr *supervisor.ProcessError
+	if assert.True(t, errors.As(p.Wait(), &processErr)) {
+		assert.Equal(t, 7, processErr.Code)
+	}
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		done := len(reaped) > 0
+		mu.Unlock()
+		if done {
+			break
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	mu.Lock()
+	assert.Equal(t, []int{orphan}, reaped)
+	mu.Unlock()
+	assert.NoError(t, child.Wait())
+
+	assert.NoError(t, r.Close())
+	assert.NoError(t, r.Wait())
+}
+
+func TestReaper_Conformance(t *testing.T) {
+	supervisortest.Conformance(t, func() supervisor.Component {
+		return supervisor.NewReaper(context.Background(), supervisor.ReaperConfig{})
+	})
+}
@@ -0,0 +1,200 @@
+package supervisor
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Phase is lifecycle phase of supervised Component
+type Phase string
+
+const (
+	// PhaseOpen is Open() method of Component
+	PhaseOpen Phase = "open"
+
+	// PhaseClose is Close() method of Component
+	PhaseClose Phase = "close"
+
+	// PhaseWait is Wait() method of Component
+	PhaseWait Phase = "wait"
+)
+
+// ShutdownEvent is completion of Close() or Wait() method of supervised
+// Component.
+type ShutdownEvent struct {
+
+	// Time is completion time
+	Time time.Time
+
+	// Component is name of Component. See Named.
+	Component string
+
+	// Phase is Component method
+	Phase Phase
+
+	// Duration is time elapsed from Close() call of Component. Duration is
+	// zero if Component exited before Close() call.
+	Duration time.Duration
+
+	// Err is error returned by Component method
+	Err error
+}
+
+// MarshalJSON implements json.Marshaler
+func (e ShutdownEvent) MarshalJSON() (data []byte, err error) {
+	return json.Marshal(struct {
+		Time      time.Time     `json:"time"`
+		Component string        `json:"component"`
+		Phase     Phase         `json:"phase"`
+		Duration  time.Duration `json:"duration"`
+		Err       string        `json:"error,omitempty"`
+	}{
+		Time:      e.Time,
+		Component: e.Component,
+		Phase:     e.Phase,
+		Duration:  e.Duration,
+		Err:       errorString(e.Err),
+	})
+}
+
+// String returns event as text line
+func (e ShutdownEvent) String() string {
+	line := e.Time.Format(time.RFC3339Nano) + " " + e.Component + " " + string(e.Phase)
+	if e.Duration > 0 {
+		line += " in " + e.Duration.String()
+	}
+	if e.Err != nil {
+		line += ": " + e.Err.Error()
+	}
+	return line
+}
+
+// ShutdownReport describes shutdown of supervisor.
+type ShutdownReport struct {
+
+	// Time is shutdown start time. Time is zero if shutdown is not started.
+	Time time.Time
+
+	// Initiator is name of Component which initiated shutdown by failed
+	// Open() or exited Wait(). Initiator is empty if shutdown was initiated
+	// by Close() or Context.
+	Initiator string
+
+	// Phase is Initiator phase
+	Phase Phase
+
+	// Cause is error returned by Initiator
+	Cause error
+
+	// Events is ordered timeline of Close() and Wait() completions
+	Events []ShutdownEvent
+}
+
+// MarshalJSON implements json.Marshaler
+func (r ShutdownReport) MarshalJSON() (data []byte, err error) {
+	events := r.Events
+	if events == nil {
+		events = []ShutdownEvent{}
+	}
+	return json.Marshal(struct {
+		Time      time.Time       `json:"time"`
+		Initiator string          `json:"initiator,omitempty"`
+		Phase     Phase           `json:"phase,omitempty"`
+		Cause     string          `json:"cause,omitempty"`
+		Events    []ShutdownEvent `json:"events"`
+	}{
+		Time:      r.Time,
+		Initiator: r.Initiator,
+		Phase:     r.Phase,
+		Cause:     errorString(r.Cause),
+		Events:    events,
+	})
+}
+
+// String returns report as text
+func (r ShutdownReport) String() string {
+	if r.Time.IsZero() {
+		return "not shut down"
+	}
+	var b strings.Builder
+	b.WriteString(r.Time.Format(time.RFC3339Nano) + " shutdown initiated by ")
+	if r.Initiator == "" {
+		b.WriteString("close")
+	} else {
+		b.WriteString(r.Initiator + " " + string(r.Phase))
+	}
+	if r.Cause != nil {
+		b.WriteString(": " + r.Cause.Error())
+	}
+	for _, event := range r.Events {
+		b.WriteString("\n" + event.String())
+	}
+	return b.String()
+}
+
+// shutdownRecorder records shutdown timeline
+type shutdownRecorder struct {
+	clock      Clock
+	mu         sync.Mutex
+	report     ShutdownReport
+	closeTimes map[int]time.Time
+}
+
+// initiate records shutdown start. Only first call is recorded. Use empty
+// name to record shutdown initiated by Close() or Context.
+func (r *shutdownRecorder) initiate(name string, phase Phase, cause error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.report.Time.IsZero() {
+		return
+	}
+	r.report.Time = r.clock.Now()
+	r.report.Initiator = name
+	if name != "" {
+		r.report.Phase = phase
+		r.report.Cause = cause
+	}
+}
+
+// closing records Close() call of component with given index
+func (r *shutdownRecorder) closing(index int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closeTimes == nil {
+		r.closeTimes = map[int]time.Time{}
+	}
+	r.closeTimes[index] = r.clock.Now()
+}
+
+// done records completion of Close() or Wait() of component with given index
+func (r *shutdownRecorder) done(index int, name string, phase Phase, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	event := ShutdownEvent{
+		Time:      r.clock.Now(),
+		Component: name,
+		Phase:     phase,
+		Err:       err,
+	}
+	if closeTime, ok := r.closeTimes[index]; ok {
+		event.Duration = event.Time.Sub(closeTime)
+	}
+	r.report.Events = append(r.report.Events, event)
+}
+
+func (r *shutdownRecorder) get() (report ShutdownReport) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	report = r.report
+	report.Events = append([]ShutdownEvent(nil), r.report.Events...)
+	return report
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
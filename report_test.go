@@ -0,0 +1,121 @@
+package supervisor_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/akaspin/supervisor"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func eventsOf(report supervisor.ShutdownReport, component string) (res []string) {
+	for _, event := range report.Events {
+		if event.Component == component {
+			res = append(res, string(event.Phase))
+		}
+	}
+	return res
+}
+
+func TestComposite_ShutdownReport(t *testing.T) {
+	t.Run("before shutdown", func(t *testing.T) {
+		c1 := newTestingComponent("1", nil, nil, nil)
+		sv := supervisor.NewChain(context.Background(), c1)
+		assert.NoError(t, sv.Open())
+		report := sv.ShutdownReport()
+		assert.True(t, report.Time.IsZero())
+		assert.Empty(t, report.Events)
+		assert.Equal(t, "not shut down", report.String())
+		assert.NoError(t, sv.Close())
+		assert.NoError(t, sv.Wait())
+	})
+	t.Run("close", func(t *testing.T) {
+		c1 := newTestingComponent("1", nil, nil, nil)
+		c2 := newTestingComponent("2", nil, errors.New("2"), nil)
+		sv := supervisor.NewChain(context.Background(), c1, c2)
+		assert.NoError(t, sv.Open())
+		assert.EqualError(t, sv.Close(), "2")
+		assert.NoError(t, sv.Wait())
+
+		report := sv.ShutdownReport()
+		assert.False(t, report.Time.IsZero())
+		assert.Equal(t, "", report.Initiator)
+		assert.NoError(t, report.Cause)
+		assert.Len(t, report.Events, 4)
+		assert.ElementsMatch(t, []string{"close", "wait"}, eventsOf(report, "1"))
+		assert.ElementsMatch(t, []string{"close", "wait"}, eventsOf(report, "2"))
+		assert.Equal(t, "2", report.Events[0].Component)
+		assert.Contains(t, report.String(), "shutdown initiated by close")
+		assert.Contains(t, report.String(), "2 close in ")
+	})
+	t.Run("chain exit", func(t *testing.T) {
+		c1 := newTestingComponent("1", nil, nil, nil)
+		c2 := newTestingComponent("2", nil, nil, errors.New("bang"))
+		c3 := newTestingComponent("3", nil, nil, nil)
+		sv := supervisor.NewChain(context.Background(), c1, c2, c3)
+		assert.NoError(t, sv.Open())
+		close(c2.closedChan)
+		assert.EqualError(t, sv.Wait(), "bang")
+
+		report := sv.ShutdownReport()
+		assert.Equal(t, "2", report.Initiator)
+		assert.Equal(t, supervisor.PhaseWait, report.Phase)
+		assert.EqualError(t, report.Cause, "bang")
+		assert.Len(t, report.Events, 5)
+		assert.Equal(t, supervisor.ShutdownEvent{
+			Time:      report.Events[0].Time,
+			Component: "2",
+			Phase:     supervisor.PhaseWait,
+			Err:       errors.New("bang"),
+		}, report.Events[0])
+		assert.Equal(t, []string{"wait"}, eventsOf(report, "2"))
+		assert.ElementsMatch(t, []string{"close", "wait"}, eventsOf(report, "3"))
+		assert.ElementsMatch(t, []string{"close", "wait"}, eventsOf(report, "1"))
+		assert.True(t, strings.HasSuffix(strings.Split(report.String(), "\n")[0], "shutdown initiated by 2 wait: bang"))
+	})
+	t.Run("group open error", func(t *testing.T) {
+		c1 := newTestingComponent("1", nil, nil, nil)
+		c2 := newTestingComponent("2", errors.New("2"), nil, nil)
+		sv := supervisor.NewGroup(context.Background(), c1, c2)
+		assert.EqualError(t, sv.Open(), "2")
+		assert.NoError(t, sv.Wait())
+
+		report := sv.ShutdownReport()
+		assert.Equal(t, "2", report.Initiator)
+		assert.Equal(t, supervisor.PhaseOpen, report.Phase)
+		assert.EqualError(t, report.Cause, "2")
+		assert.ElementsMatch(t, []string{"close", "wait"}, eventsOf(report, "1"))
+		assert.Empty(t, eventsOf(report, "2"))
+	})
+	t.Run("json", func(t *testing.T) {
+		c1 := newTestingComponent("1", nil, nil, errors.New("bang"))
+		sv := supervisor.NewGroup(context.Background(), c1)
+		assert.NoError(t, sv.Open())
+		close(c1.closedChan)
+		assert.EqualError(t, sv.Wait(), "bang")
+
+		data, err := json.Marshal(sv.ShutdownReport())
+		assert.NoError(t, err)
+		var res struct {
+			Initiator string
+			Phase     string
+			Cause     string
+			Events    []struct {
+				Component string
+				Phase     string
+				Duration  int64
+				Error     string
+			}
+		}
+		assert.NoError(t, json.Unmarshal(data, &res))
+		assert.Equal(t, "1", res.Initiator)
+		assert.Equal(t, "wait", res.Phase)
+		assert.Equal(t, "bang", res.Cause)
+		assert.Len(t, res.Events, 1)
+		assert.Equal(t, "1", res.Events[0].Component)
+		assert.Equal(t, "wait", res.Events[0].Phase)
+		assert.Equal(t, "bang", res.Events[0].Error)
+	})
+}
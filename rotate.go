@@ -0,0 +1,124 @@
+package supervisor
+
+import (
+	"github.com/akaspin/errslice"
+	"os"
+	"strconv"
+	"sync"
+)
+
+/*
+RotatingFile is io.WriteCloser which rotates file when its size exceeds limit.
+On rotation file "name" is renamed to "name.1", "name.1" to "name.2" and so on.
+Files with numbers greater than limit are removed.
+
+RotatingFile never splits one Write() between files. If rotation fails
+RotatingFile keeps writing to file "name" and retries rotation on next
+Write().
+*/
+type RotatingFile struct {
+	name     string
+	maxSize  int64
+	maxFiles int
+
+	mu     sync.Mutex
+	file   *os.File // nil if file is not reopened after rotation
+	size   int64
+	closed bool
+}
+
+// OpenRotatingFile opens or creates file with given name for append. File
+// is rotated when its size exceeds maxSize bytes. At most maxFiles rotated
+// files are kept.
+func OpenRotatingFile(name string, maxSize int64, maxFiles int) (f *RotatingFile, err error) {
+	f = &RotatingFile{
+		name:     name,
+		maxSize:  maxSize,
+		maxFiles: maxFiles,
+	}
+	if err = f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write writes given bytes to file. Write rotates file before write if size
+// of file with written bytes exceeds limit. If rotation fails Write writes
+// bytes to reopened file and returns rotation error with number of written
+// bytes.
+func (f *RotatingFile) Write(p []byte) (n int, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+	if f.file == nil {
+		if err = f.open(); err != nil {
+			return 0, err
+		}
+	}
+	var rotateErr error
+	if f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if rotateErr = f.rotate(); f.file == nil {
+			return 0, rotateErr
+		}
+	}
+	n, err = f.file.Write(p)
+	f.size += int64(n)
+	return n, errslice.Append(rotateErr, err)
+}
+
+// Close closes file
+func (f *RotatingFile) Close() (err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed || f.file == nil {
+		f.closed = true
+		return nil
+	}
+	f.closed = true
+	err = f.file.Close()
+	f.file = nil
+	return err
+}
+
+func (f *RotatingFile) open() (err error) {
+	file, err := os.OpenFile(f.name, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+// rotate closes and shifts file and opens new one. If close or shift fails
+// rotate reopens file with original name.
+func (f *RotatingFile) rotate() (err error) {
+	if err = f.file.Close(); err == nil {
+		err = f.shift()
+	}
+	f.file = nil
+	return errslice.Append(err, f.open())
+}
+
+// shift renames file to first rotated file and shifts rotated files
+func (f *RotatingFile) shift() (err error) {
+	if f.maxFiles <= 0 {
+		return os.Remove(f.name)
+	}
+	os.Remove(f.rotated(f.maxFiles))
+	for i := f.maxFiles - 1; i > 0; i-- {
+		os.Rename(f.rotated(i), f.rotated(i+1))
+	}
+	return os.Rename(f.name, f.rotated(1))
+}
+
+func (f *RotatingFile) rotated(index int) string {
+	return f.name + "." + strconv.Itoa(index)
+}
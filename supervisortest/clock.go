@@ -0,0 +1,159 @@
+package supervisortest
+
+import (
+	"github.com/akaspin/supervisor"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock is manually advanced supervisor.Clock. Timers and tickers created by
+// Clock fire only on Advance() calls.
+type Clock struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	now    time.Time
+	timers []*clockTimer
+}
+
+// NewClock returns new Clock with given current time
+func NewClock(now time.Time) (c *Clock) {
+	c = &Clock{
+		now: now,
+	}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// Now returns current Clock time
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After returns channel which receives current Clock time after Clock is
+// advanced by given duration.
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+// NewTimer returns new Timer which fires after Clock is advanced by given
+// duration.
+func (c *Clock) NewTimer(d time.Duration) supervisor.Timer {
+	return c.add(d, 0)
+}
+
+// NewTicker returns new Ticker which ticks each time Clock is advanced by
+// given duration.
+func (c *Clock) NewTicker(d time.Duration) supervisor.Ticker {
+	return clockTicker{c.add(d, d)}
+}
+
+// Advance advances Clock by given duration and fires all expired timers and
+// tickers.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	end := c.now.Add(d)
+	for {
+		sort.Slice(c.timers, func(i, j int) bool {
+			return c.timers[i].deadline.Before(c.timers[j].deadline)
+		})
+		if len(c.timers) == 0 || c.timers[0].deadline.After(end) {
+			break
+		}
+		timer := c.timers[0]
+		c.now = timer.deadline
+		select {
+		case timer.c <- c.now:
+		default:
+		}
+		if timer.period > 0 {
+			timer.deadline = timer.deadline.Add(timer.period)
+			continue
+		}
+		c.timers = c.timers[1:]
+	}
+	c.now = end
+	c.cond.Broadcast()
+}
+
+// BlockUntil blocks until given number of timers and tickers are active
+func (c *Clock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.timers) < n {
+		c.cond.Wait()
+	}
+}
+
+func (c *Clock) add(d, period time.Duration) (timer *clockTimer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	timer = &clockTimer{
+		clock:  c,
+		c:      make(chan time.Time, 1),
+		period: period,
+	}
+	c.schedule(timer, d)
+	return timer
+}
+
+// schedule schedules timer and returns true if timer was active. Should be
+// called under lock.
+func (c *Clock) schedule(timer *clockTimer, d time.Duration) (active bool) {
+	active = c.unschedule(timer)
+	timer.deadline = c.now.Add(d)
+	c.timers = append(c.timers, timer)
+	c.cond.Broadcast()
+	return active
+}
+
+// unschedule removes timer and returns true if timer was active. Should be
+// called under lock.
+func (c *Clock) unschedule(timer *clockTimer) (active bool) {
+	for i, t := range c.timers {
+		if t == timer {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			c.cond.Broadcast()
+			return true
+		}
+	}
+	return false
+}
+
+type clockTimer struct {
+	clock    *Clock
+	c        chan time.Time
+	deadline time.Time
+	period   time.Duration
+}
+
+func (t *clockTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *clockTimer) Stop() (ok bool) {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	return t.clock.unschedule(t)
+}
+
+func (t *clockTimer) Reset(d time.Duration) (ok bool) {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	return t.clock.schedule(t, d)
+}
+
+type clockTicker struct {
+	timer *clockTimer
+}
+
+func (t clockTicker) C() <-chan time.Time {
+	return t.timer.c
+}
+
+func (t clockTicker) Stop() {
+	t.timer.Stop()
+}
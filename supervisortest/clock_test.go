@@ -0,0 +1,51 @@
+package supervisortest_test
+
+import (
+	"github.com/akaspin/supervisor/supervisortest"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestClock(t *testing.T) {
+	start := time.Unix(0, 0)
+	t.Run("timer", func(t *testing.T) {
+		clock := supervisortest.NewClock(start)
+		timer := clock.NewTimer(time.Second)
+		clock.Advance(time.Millisecond * 999)
+		select {
+		case <-timer.C():
+			t.Fatal("timer fired")
+		default:
+		}
+		clock.Advance(time.Millisecond)
+		assert.Equal(t, start.Add(time.Second), <-timer.C())
+		assert.False(t, timer.Stop())
+		assert.False(t, timer.Reset(time.Second))
+		assert.True(t, timer.Stop())
+		clock.Advance(time.Second)
+		select {
+		case <-timer.C():
+			t.Fatal("stopped timer fired")
+		default:
+		}
+	})
+	t.Run("ticker", func(t *testing.T) {
+		clock := supervisortest.NewClock(start)
+		ticker := clock.NewTicker(time.Second)
+		clock.Advance(time.Second)
+		assert.Equal(t, start.Add(time.Second), <-ticker.C())
+		clock.Advance(time.Second * 3)
+		assert.Equal(t, start.Add(time.Second*2), <-ticker.C())
+		assert.Equal(t, start.Add(time.Second*4), clock.Now())
+		ticker.Stop()
+	})
+	t.Run("block until", func(t *testing.T) {
+		clock := supervisortest.NewClock(start)
+		go func() {
+			<-clock.After(time.Second)
+		}()
+		clock.BlockUntil(1)
+		clock.Advance(time.Second)
+	})
+}
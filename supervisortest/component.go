@@ -0,0 +1,130 @@
+package supervisortest
+
+import (
+	"github.com/akaspin/supervisor"
+	"sync"
+	"time"
+)
+
+// Script describes behaviour of one Component method
+type Script struct {
+
+	// Err is error returned by method
+	Err error
+
+	// Delay is delay before method returns
+	Delay time.Duration
+
+	// Block blocks method until channel is closed
+	Block <-chan struct{}
+}
+
+func (s Script) run() (err error) {
+	if s.Block != nil {
+		<-s.Block
+	}
+	if s.Delay > 0 {
+		time.Sleep(s.Delay)
+	}
+	return s.Err
+}
+
+/*
+Component is scriptable fake supervisor.Component. By default all methods of
+Component return nil and Wait() blocks until Close() or Exit() is called.
+Use On() to change behaviour of methods.
+
+Component reports completion of each method to Recorder as "<name>-<phase>"
+events where phase is one of "open", "close" or "wait".
+*/
+type Component struct {
+	name     string
+	recorder *Recorder
+
+	mu      sync.Mutex
+	scripts map[supervisor.Phase]Script
+	calls   map[supervisor.Phase]int
+	events  *Recorder
+
+	exitOnce sync.Once
+	exitChan chan struct{}
+}
+
+// NewComponent returns new Component with given name. Recorder may be nil.
+func NewComponent(name string, recorder *Recorder) (c *Component) {
+	return &Component{
+		name:     name,
+		recorder: recorder,
+		scripts:  map[supervisor.Phase]Script{},
+		calls:    map[supervisor.Phase]int{},
+		events:   NewRecorder(),
+		exitChan: make(chan struct{}),
+	}
+}
+
+// On sets Script for given phase and returns Component
+func (c *Component) On(phase supervisor.Phase, script Script) *Component {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.scripts[phase] = script
+	return c
+}
+
+// Exit makes Wait() to return without Close()
+func (c *Component) Exit() {
+	c.exitOnce.Do(func() {
+		close(c.exitChan)
+	})
+}
+
+// Calls returns number of calls of method
+func (c *Component) Calls(phase supervisor.Phase) (n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls[phase]
+}
+
+// Events returns Recorder with own events of Component. Events are recorded
+// as phase names.
+func (c *Component) Events() (recorder *Recorder) {
+	return c.events
+}
+
+// Name returns Component name
+func (c *Component) Name() string {
+	return c.name
+}
+
+// Open runs Script for supervisor.PhaseOpen
+func (c *Component) Open() (err error) {
+	return c.call(supervisor.PhaseOpen, nil)
+}
+
+// Close runs Script for supervisor.PhaseClose and makes Wait() to return
+func (c *Component) Close() (err error) {
+	return c.call(supervisor.PhaseClose, c.Exit)
+}
+
+// Wait blocks until Close() or Exit() is called and runs Script for
+// supervisor.PhaseWait
+func (c *Component) Wait() (err error) {
+	<-c.exitChan
+	return c.call(supervisor.PhaseWait, nil)
+}
+
+func (c *Component) call(phase supervisor.Phase, after func()) (err error) {
+	c.mu.Lock()
+	c.calls[phase]++
+	script := c.scripts[phase]
+	c.mu.Unlock()
+
+	err = script.run()
+	c.events.Record(string(phase))
+	if c.recorder != nil {
+		c.recorder.Record(c.name + "-" + string(phase))
+	}
+	if after != nil {
+		after()
+	}
+	return err
+}
@@ -0,0 +1,74 @@
+package supervisortest_test
+
+import (
+	"context"
+	"errors"
+	"github.com/akaspin/supervisor"
+	"github.com/akaspin/supervisor/supervisortest"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestComponent(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		recorder := supervisortest.NewRecorder()
+		c := supervisortest.NewComponent("1", recorder)
+		assert.NoError(t, c.Open())
+		assert.NoError(t, c.Close())
+		assert.NoError(t, c.Wait())
+		recorder.Assert(t, "1-open", "1-close", "1-wait")
+		c.Events().Assert(t, "open", "close", "wait")
+		assert.Equal(t, 1, c.Calls(supervisor.PhaseOpen))
+		assert.Equal(t, "1", c.Name())
+	})
+	t.Run("script", func(t *testing.T) {
+		block := make(chan struct{})
+		c := supervisortest.NewComponent("1", nil).
+			On(supervisor.PhaseOpen, supervisortest.Script{Err: errors.New("open")}).
+			On(supervisor.PhaseClose, supervisortest.Script{Block: block}).
+			On(supervisor.PhaseWait, supervisortest.Script{Delay: time.Millisecond, Err: errors.New("wait")})
+		assert.EqualError(t, c.Open(), "open")
+		closeChan := make(chan struct{})
+		go func() {
+			defer close(closeChan)
+			assert.NoError(t, c.Close())
+		}()
+		select {
+		case <-closeChan:
+			t.Fatal("close is not blocked")
+		case <-time.After(time.Millisecond * 10):
+		}
+		close(block)
+		<-closeChan
+		assert.EqualError(t, c.Wait(), "wait")
+	})
+	t.Run("exit", func(t *testing.T) {
+		c := supervisortest.NewComponent("1", nil)
+		assert.NoError(t, c.Open())
+		c.Exit()
+		assert.NoError(t, c.Wait())
+		c.Events().Assert(t, "open", "wait")
+	})
+}
+
+func TestRecorder_AssertOrder(t *testing.T) {
+	recorder := supervisortest.NewRecorder()
+	c1 := supervisortest.NewComponent("1", recorder)
+	c2 := supervisortest.NewComponent("2", recorder)
+	c3 := supervisortest.NewComponent("3", recorder)
+	sv := supervisor.NewChain(context.Background(),
+		c1,
+		supervisor.NewGroup(context.Background(), c2, c3),
+	)
+	assert.NoError(t, sv.Open())
+	assert.NoError(t, sv.Close())
+	assert.NoError(t, sv.Wait())
+	recorder.AssertOrder(t,
+		[]string{"1-open"},
+		[]string{"2-open", "3-open"},
+		[]string{"2-close", "2-wait", "3-close", "3-wait"},
+		[]string{"1-close"},
+		[]string{"1-wait"},
+	)
+}
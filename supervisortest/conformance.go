@@ -0,0 +1,192 @@
+// Package supervisortest provides utilities to test supervisor Components.
+package supervisortest
+
+import (
+	"github.com/akaspin/supervisor"
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+var (
+	// ConformanceIterations is number of iterations of each Conformance
+	// scenario.
+	ConformanceIterations = 20
+
+	// ConformanceTimeout is maximum time to wait for each Component method.
+	ConformanceTimeout = time.Second * 5
+)
+
+/*
+Conformance runs battery of lifecycle, concurrency and race scenarios against
+Components returned by given factory. Factory should return new Component on
+each call. Use Conformance with -race flag to detect data races.
+
+Conformance verifies that Component:
+
+	returns the same results on repeated Open(), Close() and Wait() calls;
+	does not block on Close() before Open() and returns
+	supervisor.ErrPrematurelyClosed from subsequent Open();
+	blocks Wait() until Close() if Wait() is called before Open();
+	handles concurrent calls of all methods.
+
+If Component implements supervisor.Stateful Conformance also verifies that
+Component is in terminal state after Wait() is returned.
+*/
+func Conformance(t *testing.T, factory func() supervisor.Component) {
+	t.Helper()
+	scenarios := []struct {
+		name string
+		fn   func(t *testing.T, component supervisor.Component)
+	}{
+		{"owc", conformanceCycle},
+		{"repeated", conformanceRepeated},
+		{"close first", conformanceCloseFirst},
+		{"wait first", conformanceWaitFirst},
+		{"concurrent", conformanceConcurrent},
+		{"concurrent close", conformanceConcurrentClose},
+	}
+	for _, scenario := range scenarios {
+		scenario := scenario
+		t.Run(scenario.name, func(t *testing.T) {
+			for i := 0; i < ConformanceIterations; i++ {
+				t.Run(strconv.Itoa(i), func(t *testing.T) {
+					scenario.fn(t, factory())
+				})
+			}
+		})
+	}
+}
+
+func conformanceCycle(t *testing.T, component supervisor.Component) {
+	assert.NoError(t, call(t, "open", component.Open))
+	call(t, "close", component.Close)
+	call(t, "wait", component.Wait)
+	assertTerminal(t, component)
+}
+
+func conformanceRepeated(t *testing.T, component supervisor.Component) {
+	openErr := call(t, "open", component.Open)
+	assert.Equal(t, openErr, call(t, "open", component.Open), "repeated open")
+	closeErr := call(t, "close", component.Close)
+	assert.Equal(t, closeErr, call(t, "close", component.Close), "repeated close")
+	waitErr := call(t, "wait", component.Wait)
+	assert.Equal(t, waitErr, call(t, "wait", component.Wait), "repeated wait")
+	assert.Equal(t, openErr, call(t, "open", component.Open), "open after wait")
+	assertTerminal(t, component)
+}
+
+func conformanceCloseFirst(t *testing.T, component supervisor.Component) {
+	call(t, "close", component.Close)
+	assert.Equal(t, supervisor.ErrPrematurelyClosed, call(t, "open", component.Open), "open after close")
+	call(t, "wait", component.Wait)
+	assertTerminal(t, component)
+}
+
+func conformanceWaitFirst(t *testing.T, component supervisor.Component) {
+	waitChan := make(chan struct{})
+	go func() {
+		defer close(waitChan)
+		component.Wait()
+	}()
+	assert.NoError(t, call(t, "open", component.Open))
+	select {
+	case <-waitChan:
+		t.Error("wait exited before close")
+		return
+	default:
+	}
+	call(t, "close", component.Close)
+	select {
+	case <-waitChan:
+	case <-time.After(ConformanceTimeout):
+		t.Error("wait is not exited after close")
+	}
+	assertTerminal(t, component)
+}
+
+func conformanceConcurrent(t *testing.T, component supervisor.Component) {
+	const n = 4
+	var wg sync.WaitGroup
+	openErrs := make([]error, n)
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			defer wg.Done()
+			openErrs[i] = call(t, "open", component.Open)
+		}(i)
+	}
+	wg.Wait()
+	for i := 1; i < n; i++ {
+		assert.Equal(t, openErrs[0], openErrs[i], "concurrent open")
+	}
+
+	waitErrs := make([]error, n)
+	wg.Add(n * 2)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			defer wg.Done()
+			waitErrs[i] = call(t, "wait", component.Wait)
+		}(i)
+		go func() {
+			defer wg.Done()
+			call(t, "close", component.Close)
+		}()
+	}
+	wg.Wait()
+	for i := 1; i < n; i++ {
+		assert.Equal(t, waitErrs[0], waitErrs[i], "concurrent wait")
+	}
+	assertTerminal(t, component)
+}
+
+func conformanceConcurrentClose(t *testing.T, component supervisor.Component) {
+	var wg sync.WaitGroup
+	var closeErr error
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		call(t, "open", component.Open)
+	}()
+	go func() {
+		defer wg.Done()
+		closeErr = call(t, "close", component.Close)
+	}()
+	go func() {
+		defer wg.Done()
+		call(t, "wait", component.Wait)
+	}()
+	wg.Wait()
+	assert.Equal(t, closeErr, call(t, "close", component.Close), "close after concurrent open")
+	call(t, "wait", component.Wait)
+	assertTerminal(t, component)
+}
+
+// call calls given method and marks test failed if method is not returned
+// in ConformanceTimeout. Call may be used from any goroutine.
+func call(t *testing.T, name string, fn func() error) (err error) {
+	t.Helper()
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- fn()
+	}()
+	timer := time.NewTimer(ConformanceTimeout)
+	defer timer.Stop()
+	select {
+	case err = <-errChan:
+		return err
+	case <-timer.C:
+		t.Errorf("%s is not returned in %s", name, ConformanceTimeout)
+		return nil
+	}
+}
+
+func assertTerminal(t *testing.T, component supervisor.Component) {
+	t.Helper()
+	if stateful, ok := component.(supervisor.Stateful); ok {
+		state := stateful.State()
+		assert.True(t, state.IsTerminal(), "state %s is not terminal", state)
+	}
+}
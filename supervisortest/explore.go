@@ -0,0 +1,388 @@
+package supervisortest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/akaspin/supervisor"
+	"math/rand"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var (
+	// ErrScheduled is returned by methods of ScheduledComponent which are
+	// scheduled to fail.
+	ErrScheduled = errors.New("scheduled error")
+)
+
+/*
+Explorer systematically explores interleavings of Open(), Close() and Wait()
+returns of ScheduledComponents in supervisor tree. Each exploration is driven
+by seeded random generator and reproducible by seed.
+
+On each step Explorer waits until tree is settled and then chooses one of
+pending method calls of ScheduledComponents to return or closes the root of
+tree. Tree is settled when all goroutines running supervisor code are
+blocked. Explorer verifies that:
+
+	Open(), Close() and Wait() of each component are called at most once;
+	Close() is called only for successfully opened components;
+	each opened component is closed exactly once unless its Wait() is
+	returned before Close() is called;
+	Wait() is called for all successfully opened components;
+	Open(), Close() and Wait() of the root are returned.
+
+On violation Explorer releases all pending and subsequent calls to let tree
+exit.
+*/
+type Explorer struct {
+
+	// Seeds is number of explored seeds. Default is 100.
+	Seeds int
+
+	// FirstSeed is first explored seed. Use FirstSeed with Seeds = 1 to
+	// reproduce failed exploration.
+	FirstSeed int64
+
+	// Timeout is maximum time to wait for settled tree and for returns of
+	// the root methods after all scheduled calls are returned. Default is
+	// one second.
+	Timeout time.Duration
+}
+
+// Run explores tree built by given function for each seed
+func (e Explorer) Run(t *testing.T, build func(s *Scheduler) supervisor.Component) {
+	t.Helper()
+	seeds := e.Seeds
+	if seeds == 0 {
+		seeds = 100
+	}
+	for seed := e.FirstSeed; seed < e.FirstSeed+int64(seeds); seed++ {
+		if trace, err := e.Explore(seed, build); err != nil {
+			t.Errorf("seed %d: %s; trace: %v", seed, err, trace)
+		}
+	}
+}
+
+// Explore explores tree built by given function with given seed and returns
+// trace of choices and first found violation.
+func (e Explorer) Explore(seed int64, build func(s *Scheduler) supervisor.Component) (trace []string, err error) {
+	timeout := e.Timeout
+	if timeout == 0 {
+		timeout = time.Second
+	}
+	s := newScheduler(seed)
+	err = s.explore(build(s), timeout)
+	return s.Trace(), err
+}
+
+// Scheduler controls returns of ScheduledComponents
+type Scheduler struct {
+	rand *rand.Rand
+
+	mu         sync.Mutex
+	pending    map[string]*pendingCall
+	components []*ScheduledComponent
+	trace      []string
+	teardown   bool // release all calls immediately
+}
+
+func newScheduler(seed int64) (s *Scheduler) {
+	return &Scheduler{
+		rand:    rand.New(rand.NewSource(seed)),
+		pending: map[string]*pendingCall{},
+	}
+}
+
+// Component returns new ScheduledComponent with given name. Names should be
+// unique in tree.
+func (s *Scheduler) Component(name string) (c *ScheduledComponent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c = &ScheduledComponent{
+		scheduler: s,
+		name:      name,
+		calls:     map[supervisor.Phase]int{},
+	}
+	s.components = append(s.components, c)
+	return c
+}
+
+// Trace returns labels of choices made by Scheduler
+func (s *Scheduler) Trace() (trace []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.trace...)
+}
+
+func (s *Scheduler) explore(root supervisor.Component, timeout time.Duration) (err error) {
+	defer s.releaseAll()
+	openChan := s.spawn(root.Open)
+	waitChan := s.spawn(root.Wait)
+	var closeChan chan struct{}
+	for {
+		if !waitSettled(timeout) {
+			return errors.New("tree is not settled")
+		}
+		options := s.options()
+		if closeChan == nil && !isDone(waitChan) {
+			options = append(options, "root-close")
+		}
+		if len(options) == 0 {
+			break
+		}
+		choice := options[s.rand.Intn(len(options))]
+		s.mu.Lock()
+		s.trace = append(s.trace, choice)
+		s.mu.Unlock()
+		if choice == "root-close" {
+			closeChan = s.spawn(root.Close)
+			continue
+		}
+		s.release(choice)
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for _, root := range []struct {
+		name string
+		ch   chan struct{}
+	}{
+		{"open", openChan},
+		{"wait", waitChan},
+		{"close", closeChan},
+	} {
+		if root.ch == nil {
+			continue
+		}
+		select {
+		case <-root.ch:
+		case <-timer.C:
+			return fmt.Errorf("root %s is not returned", root.name)
+		}
+	}
+	return s.verify()
+}
+
+// spawn calls given function in goroutine and returns channel which will
+// be closed after function returns
+func (s *Scheduler) spawn(fn func() error) (done chan struct{}) {
+	done = make(chan struct{})
+	go func() {
+		defer close(done)
+		fn()
+	}()
+	return done
+}
+
+// releaseAll releases all pending calls and makes subsequent calls return
+// immediately
+func (s *Scheduler) releaseAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.teardown = true
+	for label, call := range s.pending {
+		delete(s.pending, label)
+		call.release <- nil
+	}
+}
+
+// waitSettled blocks until all goroutines running supervisor code except
+// explorers are blocked. WaitSettled returns false if goroutines are not
+// blocked in given timeout.
+func waitSettled(timeout time.Duration) (ok bool) {
+	deadline := time.Now().Add(timeout)
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n == len(buf) {
+			buf = make([]byte, len(buf)*2)
+			continue
+		}
+		if isSettled(buf[:n]) {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		runtime.Gosched()
+		time.Sleep(time.Microsecond * 100)
+	}
+}
+
+// isSettled returns true if no goroutine in given dump running supervisor
+// code is runnable
+func isSettled(dump []byte) (ok bool) {
+	for _, g := range bytes.Split(dump, []byte("\n\n")) {
+		if !bytes.Contains(g, []byte("github.com/akaspin/supervisor")) ||
+			bytes.Contains(g, []byte("supervisortest.waitSettled")) {
+			continue
+		}
+		header := g
+		if i := bytes.IndexByte(g, '\n'); i >= 0 {
+			header = g[:i]
+		}
+		start, end := bytes.IndexByte(header, '['), bytes.IndexByte(header, ']')
+		if start < 0 || end < start {
+			continue
+		}
+		state := string(bytes.SplitN(header[start+1:end], []byte(","), 2)[0])
+		switch state {
+		case "running", "runnable", "syscall":
+			return false
+		}
+	}
+	return true
+}
+
+// options returns sorted labels of all possible choices
+func (s *Scheduler) options() (options []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for label, call := range s.pending {
+		options = append(options, label)
+		if call.component.fallible && call.phase != supervisor.PhaseClose {
+			options = append(options, label+"-error")
+		}
+	}
+	sort.Strings(options)
+	return options
+}
+
+func (s *Scheduler) release(choice string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var err error
+	label := choice
+	if strings.HasSuffix(choice, "-error") {
+		label = strings.TrimSuffix(choice, "-error")
+		err = ErrScheduled
+	}
+	call := s.pending[label]
+	delete(s.pending, label)
+	if call.phase == supervisor.PhaseWait {
+		call.component.waited = true
+	}
+	call.release <- err
+}
+
+func (s *Scheduler) enqueue(c *ScheduledComponent, phase supervisor.Phase) (release chan error, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c.calls[phase]++
+	if c.calls[phase] > 1 {
+		return nil, fmt.Errorf("%s is called more than once", phase)
+	}
+	if phase == supervisor.PhaseClose && !c.opened {
+		return nil, errors.New("close is called for not opened component")
+	}
+	release = make(chan error, 1)
+	if s.teardown {
+		release <- nil
+		return release, nil
+	}
+	s.pending[c.name+"-"+string(phase)] = &pendingCall{
+		component: c,
+		phase:     phase,
+		release:   release,
+	}
+	return release, nil
+}
+
+func (s *Scheduler) verify() (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.components {
+		if c.err != nil {
+			return fmt.Errorf("%s: %s", c.name, c.err)
+		}
+		if c.opened && c.calls[supervisor.PhaseWait] == 0 {
+			return fmt.Errorf("%s: wait is not called for opened component", c.name)
+		}
+		if c.opened && c.calls[supervisor.PhaseClose] == 0 && !c.waited {
+			return fmt.Errorf("%s: opened component is not closed", c.name)
+		}
+	}
+	return nil
+}
+
+func isDone(ch chan struct{}) (ok bool) {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+type pendingCall struct {
+	component *ScheduledComponent
+	phase     supervisor.Phase
+	release   chan error
+}
+
+// ScheduledComponent is supervisor.Component which methods return only
+// after Scheduler chooses them. Wait() of ScheduledComponent may return
+// before Close() is called.
+type ScheduledComponent struct {
+	scheduler *Scheduler
+	name      string
+	fallible  bool
+
+	// guarded by scheduler lock
+	calls  map[supervisor.Phase]int
+	opened bool
+	waited bool  // Wait() is returned
+	err    error // first contract violation
+}
+
+// Fallible allows Scheduler to return ErrScheduled from Open() and Wait()
+func (c *ScheduledComponent) Fallible() *ScheduledComponent {
+	c.scheduler.mu.Lock()
+	defer c.scheduler.mu.Unlock()
+	c.fallible = true
+	return c
+}
+
+// Name returns ScheduledComponent name
+func (c *ScheduledComponent) Name() string {
+	return c.name
+}
+
+// Open blocks until Scheduler chooses it
+func (c *ScheduledComponent) Open() (err error) {
+	err = c.call(supervisor.PhaseOpen)
+	if err == nil {
+		c.scheduler.mu.Lock()
+		c.opened = true
+		c.scheduler.mu.Unlock()
+	}
+	return err
+}
+
+// Close blocks until Scheduler chooses it
+func (c *ScheduledComponent) Close() (err error) {
+	return c.call(supervisor.PhaseClose)
+}
+
+// Wait blocks until Scheduler chooses it
+func (c *ScheduledComponent) Wait() (err error) {
+	return c.call(supervisor.PhaseWait)
+}
+
+func (c *ScheduledComponent) call(phase supervisor.Phase) (err error) {
+	release, err := c.scheduler.enqueue(c, phase)
+	if err != nil {
+		c.scheduler.mu.Lock()
+		if c.err == nil {
+			c.err = err
+		}
+		c.scheduler.mu.Unlock()
+		return err
+	}
+	return <-release
+}
@@ -0,0 +1,47 @@
+package supervisortest_test
+
+import (
+	"context"
+	"github.com/akaspin/supervisor"
+	"github.com/akaspin/supervisor/supervisortest"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// doubleClose closes supervised component twice
+type doubleClose struct {
+	supervisor.Component
+}
+
+func (c doubleClose) Close() (err error) {
+	c.Component.Close()
+	return c.Component.Close()
+}
+
+func TestExplorer_Explore(t *testing.T) {
+	explorer := supervisortest.Explorer{}
+	t.Run("reproducible", func(t *testing.T) {
+		build := func(s *supervisortest.Scheduler) supervisor.Component {
+			return supervisor.NewGroup(context.Background(), s.Component("1").Fallible(), s.Component("2"))
+		}
+		trace1, err := explorer.Explore(42, build)
+		assert.NoError(t, err)
+		trace2, err := explorer.Explore(42, build)
+		assert.NoError(t, err)
+		assert.Equal(t, trace1, trace2)
+		assert.NotEmpty(t, trace1)
+	})
+	t.Run("violation", func(t *testing.T) {
+		var found bool
+		for seed := int64(0); seed < 10 && !found; seed++ {
+			_, err := explorer.Explore(seed, func(s *supervisortest.Scheduler) supervisor.Component {
+				return supervisor.NewChain(context.Background(), doubleClose{s.Component("1")})
+			})
+			found = err != nil
+			if found {
+				assert.EqualError(t, err, "1: close is called more than once")
+			}
+		}
+		assert.True(t, found)
+	})
+}
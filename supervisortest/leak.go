@@ -0,0 +1,34 @@
+package supervisortest
+
+import (
+	"github.com/akaspin/supervisor"
+	"testing"
+	"time"
+)
+
+// LeakTimeout is maximum time to wait for exit of goroutines owned by tree
+var LeakTimeout = time.Second
+
+// VerifyNoLeaks fails test if goroutines owned by tree with given root are
+// not exited in LeakTimeout. VerifyNoLeaks should be called after Wait() of
+// the root is returned.
+func VerifyNoLeaks(t testing.TB, root supervisor.Component) {
+	t.Helper()
+	deadline := time.Now().Add(LeakTimeout)
+	for {
+		leaks, err := supervisor.Leaks(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(leaks) == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			for _, leak := range leaks {
+				t.Errorf("leak: %s", leak)
+			}
+			return
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+}
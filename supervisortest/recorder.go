@@ -0,0 +1,73 @@
+package supervisortest
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sort"
+	"sync"
+	"testing"
+)
+
+// Recorder records global ordering of events
+type Recorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+// NewRecorder returns new Recorder
+func NewRecorder() (r *Recorder) {
+	return &Recorder{}
+}
+
+// Record records given event
+func (r *Recorder) Record(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+// Events returns all recorded events
+func (r *Recorder) Events() (events []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.events...)
+}
+
+// Assert asserts that Recorder contains exactly given events in given order
+func (r *Recorder) Assert(t testing.TB, events ...string) (ok bool) {
+	t.Helper()
+	return assert.Equal(t, events, r.Events())
+}
+
+/*
+AssertOrder asserts that Recorder contains given groups of events in given
+order. Events inside each group may be recorded in any order. Use AssertOrder
+to check ordering of concurrent components:
+
+	recorder.AssertOrder(t,
+		[]string{"1-open", "2-open"},
+		[]string{"1-close", "2-close"},
+	)
+*/
+func (r *Recorder) AssertOrder(t testing.TB, groups ...[]string) (ok bool) {
+	t.Helper()
+	events := r.Events()
+	var expect, actual []string
+	offset := 0
+	for _, group := range groups {
+		expect = append(expect, sorted(group)...)
+		end := offset + len(group)
+		if end > len(events) {
+			end = len(events)
+		}
+		actual = append(actual, sorted(events[offset:end])...)
+		offset = end
+	}
+	actual = append(actual, events[offset:]...)
+	return assert.Equal(t, expect, actual)
+}
+
+func sorted(events []string) (res []string) {
+	res = append([]string(nil), events...)
+	sort.Strings(res)
+	return res
+}
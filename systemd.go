@@ -0,0 +1,267 @@
+package supervisor
+
+import (
+	"context"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Notifier sends state notifications to service manager with systemd
+// notify protocol. Notifier with empty socket discards all notifications.
+type Notifier struct {
+	socket string
+}
+
+// NewNotifier returns Notifier which sends notifications to unix datagram
+// socket with given path. Path which starts with "@" is address in abstract
+// namespace.
+func NewNotifier(socket string) (n *Notifier) {
+	return &Notifier{
+		socket: socket,
+	}
+}
+
+// NewEnvNotifier returns Notifier which sends notifications to socket from
+// NOTIFY_SOCKET environment variable
+func NewEnvNotifier() (n *Notifier) {
+	return NewNotifier(os.Getenv("NOTIFY_SOCKET"))
+}
+
+// Enabled returns true if Notifier has socket
+func (n *Notifier) Enabled() (ok bool) {
+	return n.socket != ""
+}
+
+// Notify sends given "KEY=VALUE" assignments in one datagram
+func (n *Notifier) Notify(assignments ...string) (err error) {
+	if n.socket == "" {
+		return nil
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{
+		Name: n.socket,
+		Net:  "unixgram",
+	})
+	if err != nil {
+		return err
+	}
+	_, err = conn.Write([]byte(strings.Join(assignments, "\n")))
+	if closeErr := conn.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// SystemdConfig configures Systemd
+type SystemdConfig struct {
+
+	// Socket is notification socket. Default is value of NOTIFY_SOCKET
+	// environment variable.
+	Socket string
+
+	// WatchdogInterval is interval between watchdog pings. Default is half
+	// of WATCHDOG_USEC environment variable if WATCHDOG_PID is not set or
+	// equal to current process ID. Zero disables watchdog pings.
+	WatchdogInterval time.Duration
+}
+
+/*
+Systemd wraps the root of supervisor tree and reports its state to systemd
+with notify protocol. Use Systemd with services of Type=notify.
+
+Systemd sends "READY=1" after Open() of the root is returned, "STOPPING=1" on
+first Close() and "STATUS=" with State of the root on each transition. These
+notifications are sent in order by background goroutine which exits after
+Wait() or failed Open(). If watchdog is enabled Systemd sends "WATCHDOG=1"
+only while liveness of tree is not failed. See HealthHandler.
+
+If notification socket is not set Systemd only supervises the root.
+*/
+type Systemd struct {
+	Component
+	ctx      context.Context
+	cancel   context.CancelFunc
+	notifier *Notifier
+	interval time.Duration
+	clock    Clock
+
+	mu          sync.Mutex
+	unsubscribe func()
+	pending     []string      // queued notifications
+	stopped     bool          // notifications are stopped
+	notifyChan  chan struct{} // signals queued notifications
+	stopOnce    sync.Once     // guards "STOPPING=1"
+	wg          sync.WaitGroup
+
+	openOnce sync.Once
+	openErr  error
+}
+
+// NewSystemd returns new Systemd which wraps given root. Systemd uses Clock
+// from given Context.
+func NewSystemd(ctx context.Context, root Component, config SystemdConfig) (s *Systemd) {
+	if config.Socket == "" {
+		config.Socket = os.Getenv("NOTIFY_SOCKET")
+	}
+	if config.WatchdogInterval == 0 {
+		config.WatchdogInterval = watchdogIntervalFromEnv()
+	}
+	s = &Systemd{
+		Component:  root,
+		notifier:   NewNotifier(config.Socket),
+		interval:   config.WatchdogInterval,
+		clock:      ClockFrom(ctx),
+		notifyChan: make(chan struct{}, 1),
+	}
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	return s
+}
+
+// Unwrap returns the root
+func (s *Systemd) Unwrap() Component {
+	return s.Component
+}
+
+// Open opens the root and notifies readiness. Repeated calls return the
+// result of the first call.
+func (s *Systemd) Open() (err error) {
+	s.openOnce.Do(func() {
+		s.openErr = s.open()
+	})
+	return s.openErr
+}
+
+func (s *Systemd) open() (err error) {
+	if s.notifier.Enabled() {
+		s.wg.Add(1)
+		go s.send()
+	}
+	if stateful := statefulOf(s.Component); stateful != nil && s.notifier.Enabled() {
+		unsubscribe := stateful.Subscribe(func(transition Transition) {
+			status := transition.To.String()
+			if transition.Err != nil {
+				status += ": " + transition.Err.Error()
+			}
+			s.notify("STATUS=" + status)
+		})
+		s.mu.Lock()
+		s.unsubscribe = unsubscribe
+		s.mu.Unlock()
+	}
+	if err = s.Component.Open(); err != nil {
+		s.cancel()
+		s.stop()
+		s.wg.Wait()
+		return err
+	}
+	s.notify("READY=1")
+	if s.interval > 0 && s.notifier.Enabled() {
+		s.wg.Add(1)
+		go s.watchdog()
+	}
+	return nil
+}
+
+// Close notifies stopping and closes the root
+func (s *Systemd) Close() (err error) {
+	s.stopOnce.Do(func() {
+		s.notify("STOPPING=1")
+	})
+	s.cancel()
+	return s.Component.Close()
+}
+
+// Wait waits for the root. Open() called after Wait() is returned does not
+// open the root and returns ErrPrematurelyClosed.
+func (s *Systemd) Wait() (err error) {
+	err = s.Component.Wait()
+	s.openOnce.Do(func() {
+		s.openErr = ErrPrematurelyClosed
+	})
+	s.cancel()
+	s.stop()
+	s.wg.Wait()
+	return err
+}
+
+// notify queues given notification
+func (s *Systemd) notify(state string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped || !s.notifier.Enabled() {
+		return
+	}
+	s.pending = append(s.pending, state)
+	select {
+	case s.notifyChan <- struct{}{}:
+	default:
+	}
+}
+
+// stop unsubscribes from transitions of the root and stops sending after
+// queued notifications
+func (s *Systemd) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.unsubscribe != nil {
+		s.unsubscribe()
+		s.unsubscribe = nil
+	}
+	s.stopped = true
+	select {
+	case s.notifyChan <- struct{}{}:
+	default:
+	}
+}
+
+// send sends queued notifications until stop
+func (s *Systemd) send() {
+	defer s.wg.Done()
+	for range s.notifyChan {
+		s.mu.Lock()
+		pending, stopped := s.pending, s.stopped
+		s.pending = nil
+		s.mu.Unlock()
+		for _, state := range pending {
+			s.notifier.Notify(state)
+		}
+		if stopped {
+			return
+		}
+	}
+}
+
+// watchdog sends watchdog pings while tree is alive
+func (s *Systemd) watchdog() {
+	defer s.wg.Done()
+	ticker := s.clock.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C():
+		}
+		ctx, cancel := context.WithTimeout(s.ctx, s.interval)
+		ok, _ := checkHealth(ctx, s.Component, false)
+		cancel()
+		if ok {
+			s.notifier.Notify("WATCHDOG=1")
+		}
+	}
+}
+
+// watchdogIntervalFromEnv returns half of watchdog timeout from environment
+func watchdogIntervalFromEnv() time.Duration {
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+	return time.Duration(usec) * time.Microsecond / 2
+}
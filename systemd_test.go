@@ -0,0 +1,160 @@
+package supervisor_test
+
+import (
+	"context"
+	"errors"
+	"github.com/akaspin/supervisor"
+	"github.com/akaspin/supervisor/supervisortest"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// listenNotify listens unixgram socket which stands in for systemd
+func listenNotify(t *testing.T) (socket string, conn *net.UnixConn) {
+	dir, err := os.MkdirTemp("", "notify")
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+	socket = filepath.Join(dir, "notify.sock")
+	conn, err = net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		conn.Close()
+	})
+	return socket, conn
+}
+
+// readNotify reads next notification
+func readNotify(t *testing.T, conn *net.UnixConn) string {
+	buf := make([]byte, 4096)
+	conn.SetReadDeadline(time.Now().Add(time.Second * 5))
+	n, err := conn.Read(buf)
+	assert.NoError(t, err)
+	return string(buf[:n])
+}
+
+func TestNotifier(t *testing.T) {
+	t.Run("notify", func(t *testing.T) {
+		socket, conn := listenNotify(t)
+		n := supervisor.NewNotifier(socket)
+		assert.True(t, n.Enabled())
+		assert.NoError(t, n.Notify("READY=1", "STATUS=ok"))
+		assert.Equal(t, "READY=1\nSTATUS=ok", readNotify(t, conn))
+	})
+	t.Run("disabled", func(t *testing.T) {
+		n := supervisor.NewNotifier("")
+		assert.False(t, n.Enabled())
+		assert.NoError(t, n.Notify("READY=1"))
+	})
+}
+
+func TestSystemd(t *testing.T) {
+	t.Run("lifecycle", func(t *testing.T) {
+		socket, conn := listenNotify(t)
+		clock := supervisortest.NewClock(time.Now())
+		ctx := supervisor.WithClock(context.Background(), clock)
+		c1 := newCheckedComponent("1")
+		sv := supervisor.NewGroup(ctx, c1)
+		s := supervisor.NewSystemd(ctx, sv, supervisor.SystemdConfig{
+			Socket:           socket,
+			WatchdogInterval: time.Second,
+		})
+		assert.NoError(t, s.Open())
+		assert.Equal(t, "STATUS=opening", readNotify(t, conn))
+		assert.Equal(t, "STATUS=open", readNotify(t, conn))
+		assert.Equal(t, "READY=1", readNotify(t, conn))
+
+		clock.BlockUntil(1)
+		c1.tick(clock, time.Second)
+		assert.Equal(t, "WATCHDOG=1", readNotify(t, conn))
+
+		// no pings while tree is not alive
+		c1.setErr(errors.New("dead"))
+		c1.tick(clock, time.Second)
+		c1.setErr(nil)
+		c1.tick(clock, time.Second)
+		assert.Equal(t, "WATCHDOG=1", readNotify(t, conn))
+
+		go func() {
+			for range c1.checks {
+			}
+		}()
+		assert.NoError(t, s.Close())
+		assert.NoError(t, s.Close())
+		assert.NoError(t, s.Wait())
+		assert.Equal(t, "STOPPING=1", readNotify(t, conn))
+		assert.Equal(t, "STATUS=closing", readNotify(t, conn))
+		assert.Equal(t, "STATUS=closed", readNotify(t, conn))
+		close(c1.checks)
+	})
+	t.Run("failed", func(t *testing.T) {
+		socket, conn := listenNotify(t)
+		c1 := supervisortest.NewComponent("1", nil).On(supervisor.PhaseWait, supervisortest.Script{
+			Err: errors.New("boom"),
+		})
+		sv := supervisor.NewGroup(context.Background(), c1)
+		s := supervisor.NewSystemd(context.Background(), sv, supervisor.SystemdConfig{
+			Socket: socket,
+		})
+		assert.NoError(t, s.Open())
+		assert.Equal(t, "STATUS=opening", readNotify(t, conn))
+		assert.Equal(t, "STATUS=open", readNotify(t, conn))
+		assert.Equal(t, "READY=1", readNotify(t, conn))
+		c1.Exit()
+		assert.EqualError(t, s.Wait(), "boom")
+		assert.Equal(t, "STATUS=closing", readNotify(t, conn))
+		assert.Equal(t, "STATUS=failed: boom", readNotify(t, conn))
+	})
+	t.Run("open failure", func(t *testing.T) {
+		socket, conn := listenNotify(t)
+		c1 := supervisortest.NewComponent("1", nil).On(supervisor.PhaseOpen, supervisortest.Script{
+			Err: errors.New("boom"),
+		})
+		sv := supervisor.NewGroup(context.Background(), c1)
+		s := supervisor.NewSystemd(context.Background(), sv, supervisor.SystemdConfig{
+			Socket: socket,
+		})
+		assert.EqualError(t, s.Open(), "boom")
+		assert.NoError(t, s.Close())
+		assert.NoError(t, s.Wait())
+
+		// notifications are stopped after failed open
+		assert.Equal(t, "STATUS=opening", readNotify(t, conn))
+		buf := make([]byte, 4096)
+		for {
+			conn.SetReadDeadline(time.Now().Add(time.Millisecond * 50))
+			n, err := conn.Read(buf)
+			if err != nil {
+				break
+			}
+			assert.NotEqual(t, "READY=1", string(buf[:n]))
+			assert.NotEqual(t, "STOPPING=1", string(buf[:n]))
+		}
+	})
+}
+
+func TestSystemd_Conformance(t *testing.T) {
+	socket, conn := listenNotify(t)
+	go func() {
+		buf := make([]byte, 4096)
+		for {
+			if _, err := conn.Read(buf); err != nil {
+				return
+			}
+		}
+	}()
+	supervisortest.Conformance(t, func() supervisor.Component {
+		ctx := context.Background()
+		return supervisor.NewSystemd(ctx,
+			supervisor.NewGroup(ctx, supervisor.NewControl(ctx)),
+			supervisor.SystemdConfig{
+				Socket:           socket,
+				WatchdogInterval: time.Millisecond * 10,
+			})
+	})
+}
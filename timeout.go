@@ -3,6 +3,7 @@ package supervisor
 import (
 	"context"
 	"errors"
+	"log/slog"
 	"time"
 )
 
@@ -11,10 +12,25 @@ var (
 	ErrTimeout = errors.New("timeout exceeded")
 )
 
-// Timeout supervises shutdown process of own descendant
+// Killer is implemented by Components which can be stopped forcibly
+type Killer interface {
+
+	// Kill forcibly stops Component
+	Kill() (err error)
+}
+
+// Timeout supervises shutdown process of own descendant. If supervised
+// component or any Component wrapped by it implements Killer Timeout calls
+// Kill() when timeout is exceeded.
 type Timeout struct {
+	*lifecycle
+	id        string // supervisor ID
+	name      string
+	log       eventLogger
+	trace     *tracer
 	ctx       context.Context
 	cancel    context.CancelFunc
+	clock     Clock
 	timeout   time.Duration
 	component Component
 
@@ -29,9 +45,18 @@ type Timeout struct {
 	doneErr    compositeError
 }
 
-// NewTimeout creates new Timeout
+// NewTimeout creates new Timeout. Timeout uses Clock, Logger, SpanExporter
+// and name from given Context. See WithClock, WithLogger, WithSpanExporter
+// and WithName.
 func NewTimeout(ctx context.Context, timeout time.Duration, component Component) (t *Timeout) {
+	clock := ClockFrom(ctx)
 	t = &Timeout{
+		lifecycle:  newLifecycle(clock),
+		id:         newSupervisorID("timeout"),
+		name:       baseName(PathFrom(ctx)),
+		log:        newEventLogger(ctx),
+		trace:      newTracer(ctx),
+		clock:      clock,
 		timeout:    timeout,
 		component:  component,
 		openChan:   make(chan struct{}),
@@ -39,58 +64,92 @@ func NewTimeout(ctx context.Context, timeout time.Duration, component Component)
 	}
 	t.ctx, t.cancel = context.WithCancel(ctx)
 	t.doneCtx, t.doneCancel = context.WithCancel(context.Background())
+	context.AfterFunc(t.ctx, func() {
+		if t.transit(StateClosed, nil, StateNew) {
+			// closed before open
+			t.openErr.set(ErrPrematurelyClosed)
+			close(t.openChan)
+			close(t.closedChan)
+			t.doneCancel()
+		}
+	})
 	return t
 }
 
-// Open opens supervised component and return error if any
+// Open opens supervised component and return error if any. Open returns
+// ErrPrematurelyClosed if Timeout is closed before open.
 func (t *Timeout) Open() (err error) {
-	select {
-	case <-t.openChan:
+	if t.ctx.Err() != nil || !t.transit(StateOpening, nil, StateNew) {
+		<-t.openChan
 		return t.openErr.get()
-	default:
 	}
+	defer close(t.openChan)
+	withLabels(t.ctx, t.id, nameOf(t.component, 0), func() {
+		err = t.open()
+	})
+	return err
+}
+
+func (t *Timeout) open() (err error) {
+	name := nameOf(t.component, 0)
+	trace := t.trace.child(t.component, name)
+	openSpan := trace.start(PhaseOpen)
+	openTime := t.clock.Now()
+	openErr := openComponent(t.ctx, t.component)
+	openSpan.end(openErr)
+	if openErr != nil {
+		t.log.log("fail", name, PhaseOpen, 0, openErr)
+		t.openErr.set(openErr)
+		t.closing()
+		t.exit(openErr)
+		close(t.closedChan)
+		t.doneCancel()
+		return openErr
+	}
+	t.transit(StateOpen, nil, StateOpening)
+	t.log.log("open", name, PhaseOpen, t.clock.Now().Sub(openTime), nil)
+	openTime = t.clock.Now()
+
+	// supervise close
 	go func() {
-		defer close(t.openChan)
-		if openErr := t.component.Open(); openErr != nil {
-			t.openErr.set(openErr)
-			return
-		}
-		// supervise close
-		go func() {
-			<-t.ctx.Done()
-			select {
-			case <-t.doneCtx.Done(): // already closed
-			default:
-				if closeErr := t.component.Close(); closeErr != nil {
-					t.closeErr.set(closeErr)
-				}
-				go func() {
-					select {
-					case <-time.After(t.timeout):
-						t.doneErr.set(ErrTimeout)
-						t.doneCancel()
-					case <-t.doneCtx.Done():
-					}
-				}()
+		<-t.ctx.Done()
+		t.closing()
+		select {
+		case <-t.doneCtx.Done(): // already closed
+		default:
+			closeTime := t.clock.Now()
+			closeSpan := trace.start(PhaseClose)
+			closeErr := t.component.Close()
+			closeSpan.end(closeErr)
+			if closeErr != nil {
+				t.closeErr.set(closeErr)
 			}
-			close(t.closedChan)
-		}()
-
-		// supervise wait
-		go func() {
-			if doneErr := t.component.Wait(); doneErr != nil {
+			t.log.log("close", name, PhaseClose, t.clock.Now().Sub(closeTime), closeErr)
+			timer := t.clock.NewTimer(t.timeout)
+			go func() {
 				select {
+				case <-timer.C():
+					t.log.log("timeout", name, PhaseWait, t.timeout, ErrTimeout)
+					t.done(ErrTimeout)
+					t.kill()
 				case <-t.doneCtx.Done():
-				default:
-					t.doneErr.set(doneErr)
+					timer.Stop()
 				}
-			}
-			t.doneCancel()
-			t.cancel()
-		}()
+			}()
+		}
+		close(t.closedChan)
 	}()
-	<-t.openChan
-	return t.openErr.get()
+
+	// supervise wait
+	waitSpan := trace.start(PhaseWait)
+	go func() {
+		waitErr := t.component.Wait()
+		waitSpan.end(waitErr)
+		t.log.log("exit", name, PhaseWait, t.clock.Now().Sub(openTime), waitErr)
+		t.done(waitErr)
+		t.cancel()
+	}()
+	return nil
 }
 
 // Close closes supervised component and starts timer
@@ -106,3 +165,61 @@ func (t *Timeout) Wait() (err error) {
 	<-t.doneCtx.Done()
 	return t.doneErr.get()
 }
+
+// interruptOpen interrupts Open() of supervised component
+func (t *Timeout) interruptOpen() {
+	interruptOpen(t.component)
+}
+
+// injectLogger passes child Logger to supervised component
+func (t *Timeout) injectLogger(logger *slog.Logger) {
+	injectLogger(t.component, logger)
+}
+
+// kill kills supervised component if it is Killer
+func (t *Timeout) kill() {
+	for component := t.component; component != nil; {
+		if killer, ok := component.(Killer); ok {
+			killer.Kill()
+			return
+		}
+		wrapper, ok := component.(Wrapper)
+		if !ok {
+			return
+		}
+		component = wrapper.Unwrap()
+	}
+}
+
+// done finishes Timeout with given error once
+func (t *Timeout) done(err error) {
+	if t.exit(err) {
+		t.doneErr.set(err)
+		t.doneCancel()
+	}
+}
+
+// Name returns name from Context provided on construction or name of
+// supervised component. See WithName.
+func (t *Timeout) Name() string {
+	if t.name != "" {
+		return t.name
+	}
+	return NameOf(t.component)
+}
+
+func (t *Timeout) spans() *tracer {
+	return t.trace
+}
+
+func (t *Timeout) supervisorID() string {
+	return t.id
+}
+
+func (t *Timeout) supervisorKind() string {
+	return "timeout"
+}
+
+func (t *Timeout) children() (components []Component) {
+	return []Component{t.component}
+}
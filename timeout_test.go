@@ -4,37 +4,12 @@ import (
 	"context"
 	"errors"
 	"github.com/akaspin/supervisor"
+	"github.com/akaspin/supervisor/supervisortest"
 	"github.com/stretchr/testify/assert"
 	"testing"
 	"time"
 )
 
-type component500 struct {
-	ctx    context.Context
-	cancel context.CancelFunc
-}
-
-func newComponent500() (c *component500) {
-	c = &component500{}
-	c.ctx, c.cancel = context.WithCancel(context.Background())
-	return c
-}
-
-func (*component500) Open() (err error) {
-	return nil
-}
-
-func (c *component500) Close() (err error) {
-	c.cancel()
-	return nil
-}
-
-func (c *component500) Wait() (err error) {
-	<-c.ctx.Done()
-	<-time.After(time.Millisecond * 300) // forether
-	return nil
-}
-
 func TestTimeout_Wait(t *testing.T) {
 	for i := 0; i < compositeTestIterations; i++ {
 		t.Run("ok", func(t *testing.T) {
@@ -49,11 +24,20 @@ func TestTimeout_Wait(t *testing.T) {
 		})
 		t.Run("timeout", func(t *testing.T) {
 			t.Parallel()
-			c1 := newComponent500()
-			to := supervisor.NewTimeout(context.Background(), time.Millisecond*50, c1)
+			clock := supervisortest.NewClock(time.Now())
+			block := make(chan struct{})
+			defer close(block)
+			c1 := supervisortest.NewComponent("1", nil).
+				On(supervisor.PhaseWait, supervisortest.Script{Block: block})
+			to := supervisor.NewTimeout(supervisor.WithClock(context.Background(), clock), time.Second, c1)
 			assert.NoError(t, to.Open())
 			assert.NoError(t, to.Close())
+
+			clock.Advance(time.Millisecond * 999)
+			assert.Equal(t, supervisor.StateClosing, to.State())
+			clock.Advance(time.Millisecond)
 			assert.EqualError(t, to.Wait(), supervisor.ErrTimeout.Error())
+			assert.Equal(t, supervisor.StateFailed, to.State())
 		})
 		t.Run("inside", func(t *testing.T) {
 			t.Parallel()
@@ -0,0 +1,183 @@
+package supervisor
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Span describes one Open(), Close() or Wait() call of supervised component
+type Span struct {
+
+	// ID is unique span ID
+	ID uint64
+
+	// Parent is ID of parent span. Parent is zero for root spans.
+	Parent uint64
+
+	// Component is name of supervised component
+	Component string
+
+	// Path is path of supervised component
+	Path string
+
+	// Phase is called method
+	Phase Phase
+
+	// Start is time of call
+	Start time.Time
+
+	// End is time of return
+	End time.Time
+
+	// Err is error returned by method
+	Err error
+}
+
+// Duration returns span duration
+func (s Span) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+// SpanExporter exports finished spans. ExportSpan may be called
+// concurrently.
+type SpanExporter interface {
+
+	// ExportSpan exports finished span
+	ExportSpan(span Span)
+}
+
+type spanExporterKey struct{}
+
+/*
+WithSpanExporter returns copy of Context with given SpanExporter. Chain, Group
+and Timeout created with returned Context produce spans for Open(), Close()
+and Wait() calls of supervised components.
+
+Spans of components supervised by nested supervisor are children of span of
+corresponding call of nested supervisor. Spans of components closed by
+nested supervisor itself on failure are not children of parent span.
+*/
+func WithSpanExporter(ctx context.Context, exporter SpanExporter) context.Context {
+	return context.WithValue(ctx, spanExporterKey{}, exporter)
+}
+
+var lastSpanID uint64
+
+// tracer produces spans of supervised components
+type tracer struct {
+	exporter SpanExporter
+	clock    Clock
+	path     string
+
+	mu      sync.Mutex
+	parents map[Phase]uint64 // parent spans set by upper supervisor
+}
+
+// newTracer returns tracer or nil if Context has no SpanExporter
+func newTracer(ctx context.Context) (t *tracer) {
+	exporter, _ := ctx.Value(spanExporterKey{}).(SpanExporter)
+	if exporter == nil {
+		return nil
+	}
+	return &tracer{
+		exporter: exporter,
+		clock:    ClockFrom(ctx),
+		path:     PathFrom(ctx),
+		parents:  map[Phase]uint64{},
+	}
+}
+
+// setParent sets parent span for spans of given phase
+func (t *tracer) setParent(phase Phase, id uint64) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.parents[phase] = id
+}
+
+// child allocates spans of supervised component with given name. If
+// component or any wrapped Component is traced supervisor child sets
+// allocated spans as parents of its spans.
+func (t *tracer) child(component Component, name string) (c *childTrace) {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	c = &childTrace{
+		tracer: t,
+		name:   name,
+		ids:    map[Phase]uint64{},
+		parent: map[Phase]uint64{},
+	}
+	for _, phase := range []Phase{PhaseOpen, PhaseClose, PhaseWait} {
+		c.ids[phase] = atomic.AddUint64(&lastSpanID, 1)
+		c.parent[phase] = t.parents[phase]
+	}
+	t.mu.Unlock()
+	for component != nil {
+		if traced, ok := component.(traced); ok {
+			for phase, id := range c.ids {
+				traced.spans().setParent(phase, id)
+			}
+			break
+		}
+		wrapper, ok := component.(Wrapper)
+		if !ok {
+			break
+		}
+		component = wrapper.Unwrap()
+	}
+	return c
+}
+
+// traced is implemented by supervisors which produce spans
+type traced interface {
+	spans() *tracer
+}
+
+// childTrace holds spans of supervised component
+type childTrace struct {
+	tracer *tracer
+	name   string
+	ids    map[Phase]uint64
+	parent map[Phase]uint64
+}
+
+// start starts span of given phase
+func (c *childTrace) start(phase Phase) (s *span) {
+	if c == nil {
+		return nil
+	}
+	return &span{
+		exporter: c.tracer.exporter,
+		clock:    c.tracer.clock,
+		Span: Span{
+			ID:        c.ids[phase],
+			Parent:    c.parent[phase],
+			Component: c.name,
+			Path:      joinPath(c.tracer.path, c.name),
+			Phase:     phase,
+			Start:     c.tracer.clock.Now(),
+		},
+	}
+}
+
+type span struct {
+	Span
+	exporter SpanExporter
+	clock    Clock
+}
+
+// end finishes and exports span
+func (s *span) end(err error) {
+	if s == nil {
+		return
+	}
+	s.End = s.clock.Now()
+	s.Err = err
+	s.exporter.ExportSpan(s.Span)
+}
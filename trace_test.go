@@ -0,0 +1,108 @@
+package supervisor_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/akaspin/supervisor"
+	"github.com/akaspin/supervisor/supervisortest"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+type spanCollector struct {
+	mu    sync.Mutex
+	spans []supervisor.Span
+}
+
+func (c *spanCollector) ExportSpan(span supervisor.Span) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.spans = append(c.spans, span)
+}
+
+// get returns spans with given path and phase
+func (c *spanCollector) get(path string, phase supervisor.Phase) (spans []supervisor.Span) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, span := range c.spans {
+		if span.Path == path && span.Phase == phase {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+func TestWithSpanExporter(t *testing.T) {
+	t.Run("hierarchy", func(t *testing.T) {
+		spans := &spanCollector{}
+		ctx := supervisor.WithSpanExporter(context.Background(), spans)
+		groupCtx := supervisor.WithName(ctx, "group")
+		sv := supervisor.NewChain(ctx,
+			supervisortest.NewComponent("1", nil),
+			supervisor.NewGroup(groupCtx,
+				supervisortest.NewComponent("2", nil),
+				supervisor.NewTimeout(supervisor.WithName(groupCtx, "timeout"), time.Second,
+					supervisortest.NewComponent("3", nil).
+						On(supervisor.PhaseClose, supervisortest.Script{Err: errors.New("3")}),
+				),
+			),
+		)
+		assert.NoError(t, sv.Open())
+		assert.EqualError(t, sv.Close(), "3")
+		assert.NoError(t, sv.Wait())
+
+		for _, phase := range []supervisor.Phase{supervisor.PhaseOpen, supervisor.PhaseClose, supervisor.PhaseWait} {
+			group := spans.get("group", phase)[0]
+			assert.NotZero(t, group.ID, string(phase))
+			assert.Zero(t, group.Parent, string(phase))
+			assert.Zero(t, spans.get("1", phase)[0].Parent, string(phase))
+			assert.Equal(t, group.ID, spans.get("group/2", phase)[0].Parent, string(phase))
+
+			timeout := spans.get("group/timeout", phase)[0]
+			assert.Equal(t, group.ID, timeout.Parent, string(phase))
+			assert.Equal(t, timeout.ID, spans.get("group/timeout/3", phase)[0].Parent, string(phase))
+		}
+		assert.EqualError(t, spans.get("group/timeout", supervisor.PhaseClose)[0].Err, "3")
+		assert.EqualError(t, spans.get("group/timeout/3", supervisor.PhaseClose)[0].Err, "3")
+		open := spans.get("group", supervisor.PhaseOpen)[0]
+		child := spans.get("group/2", supervisor.PhaseOpen)[0]
+		assert.False(t, child.Start.Before(open.Start))
+		assert.False(t, child.End.After(open.End))
+	})
+	t.Run("chrome trace", func(t *testing.T) {
+		var buf bytes.Buffer
+		trace := supervisor.NewChromeTrace(&buf)
+		ctx := supervisor.WithSpanExporter(context.Background(), trace)
+		sv := supervisor.NewGroup(ctx,
+			supervisortest.NewComponent("1", nil),
+		)
+		assert.NoError(t, sv.Open())
+		assert.NoError(t, sv.Close())
+		assert.NoError(t, sv.Wait())
+		assert.NoError(t, trace.Close())
+
+		var res struct {
+			TraceEvents []struct {
+				Name string            `json:"name"`
+				Ph   string            `json:"ph"`
+				Tid  int               `json:"tid"`
+				Args map[string]string `json:"args"`
+			} `json:"traceEvents"`
+		}
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &res))
+		var names []string
+		for _, event := range res.TraceEvents {
+			assert.Equal(t, 1, event.Tid)
+			if event.Ph == "M" {
+				assert.Equal(t, "1", event.Args["name"])
+				continue
+			}
+			names = append(names, event.Name)
+		}
+		assert.ElementsMatch(t, []string{"open 1", "close 1", "wait 1"}, names)
+	})
+}
@@ -4,15 +4,24 @@ import "context"
 
 // Trap can be used as watchdog in supervisor tree.
 type Trap struct {
+	*lifecycle
+	name    string
+	log     eventLogger
 	ctx     context.Context
 	cancel  context.CancelFunc
 	lastErr compositeError
 }
 
-// NewTrap returns new Trap bounded to given Context
+// NewTrap returns new Trap bounded to given Context. Trap uses Logger and
+// name from given Context. See WithLogger and WithName.
 func NewTrap(ctx context.Context) (t *Trap) {
-	t = &Trap{}
+	t = &Trap{
+		lifecycle: newLifecycle(ClockFrom(ctx)),
+		name:      baseName(PathFrom(ctx)),
+		log:       newEventLogger(ctx),
+	}
 	t.ctx, t.cancel = context.WithCancel(ctx)
+	context.AfterFunc(t.ctx, t.shutdown)
 	return t
 }
 
@@ -23,25 +32,42 @@ func (t *Trap) Trap(err error) {
 		case <-t.ctx.Done():
 			return
 		default:
+			t.log.log("fail", "", PhaseWait, 0, err)
 			t.lastErr.set(err)
 			t.cancel()
 		}
 	}
 }
 
-// Open opens Trap and never returns any errors
-func (*Trap) Open() (err error) {
-	return nil
+// Name returns name from Context provided on construction. See WithName.
+func (t *Trap) Name() string {
+	return t.name
+}
+
+// Open opens Trap. Open returns ErrPrematurelyClosed if Trap is closed
+// before open by Close() or Context. Errors accepted by Trap() before open
+// are returned by Wait().
+func (t *Trap) Open() (err error) {
+	if err = t.lifecycle.open(t.ctx); err != nil && t.lastErr.get() != nil {
+		return nil
+	}
+	return err
 }
 
 // Close closes trap
 func (t *Trap) Close() (err error) {
 	t.cancel()
+	t.shutdown()
 	return
 }
 
 // Wait returns last accepted error
 func (t *Trap) Wait() (err error) {
-	<-t.ctx.Done()
+	<-t.doneChan()
 	return t.lastErr.get()
 }
+
+func (t *Trap) shutdown() {
+	t.closing()
+	t.exit(t.lastErr.get())
+}
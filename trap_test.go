@@ -37,6 +37,29 @@ func TestTrap_Wait(t *testing.T) {
 	}
 }
 
+func TestTrap_TrapBeforeOpen(t *testing.T) {
+	t.Run("trap", func(t *testing.T) {
+		trap := supervisor.NewTrap(context.Background())
+		trap.Trap(errors.New("bang"))
+		assert.NoError(t, trap.Open())
+		assert.EqualError(t, trap.Wait(), "bang")
+	})
+	t.Run("close", func(t *testing.T) {
+		trap := supervisor.NewTrap(context.Background())
+		assert.NoError(t, trap.Close())
+		assert.Equal(t, supervisor.ErrPrematurelyClosed, trap.Open())
+		assert.NoError(t, trap.Wait())
+	})
+	t.Run("group", func(t *testing.T) {
+		ctx := context.Background()
+		trap := supervisor.NewTrap(ctx)
+		trap.Trap(errors.New("bang"))
+		group := supervisor.NewGroup(ctx, supervisor.NewControl(ctx), trap)
+		assert.NoError(t, group.Open())
+		assert.EqualError(t, group.Wait(), "bang")
+	})
+}
+
 func ExampleTrap_Wait() {
 	trap := supervisor.NewTrap(context.Background())
 	trap.Open()
@@ -0,0 +1,284 @@
+//go:build !windows
+
+package supervisor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+var (
+	// ErrUpgradeInProgress is returned by Upgrade() if another upgrade is
+	// not completed
+	ErrUpgradeInProgress = errors.New("upgrade in progress")
+
+	// ErrUpgradeNotReady is returned by Upgrade() if new process is not
+	// reported readiness in time
+	ErrUpgradeNotReady = errors.New("upgraded process is not ready")
+
+	// ErrUpgradeAborted is returned by Upgrade() if Upgrader is not open or
+	// is closed during upgrade
+	ErrUpgradeAborted = errors.New("upgrade aborted")
+)
+
+// UpgraderConfig configures Upgrader
+type UpgraderConfig struct {
+
+	// Signals triggers upgrade. Default is SIGHUP.
+	Signals []os.Signal
+
+	// ReadyTimeout is maximum time to wait for readiness of new process.
+	// Default is 30 seconds.
+	ReadyTimeout time.Duration
+
+	// Path is path of binary. Default is current executable.
+	Path string
+
+	// Args are arguments of new process without program name. Default is
+	// arguments of current process.
+	Args []string
+
+	// Env is environment of new process. Default is environment of current
+	// process.
+	Env []string
+
+	// Stdout and Stderr of new process. Default is stdout and stderr of
+	// current process.
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+/*
+Upgrader replaces running process with new copy of binary without dropping
+connections. Place Upgrader after Listeners in Chain.
+
+On one of signals or Upgrade() call Upgrader starts new process and passes
+descriptors of Listeners with socket activation protocol. New process should
+report readiness with systemd notify protocol to socket provided in
+NOTIFY_SOCKET environment variable. See Systemd. After readiness Upgrader
+exits which closes supervisor tree. If new process exits or is not ready in
+time Upgrader kills it and keeps current process running. Errors of upgrades
+triggered by signals are logged with Logger from Context. See LoggerFrom.
+
+Upgrader replaces NOTIFY_SOCKET in environment of new process. Service
+manager should allow notifications from any process (NotifyAccess=all) and
+track new main process.
+*/
+type Upgrader struct {
+	*Control
+	listeners *Listeners
+	config    UpgraderConfig
+	clock     Clock
+
+	mu        sync.Mutex
+	upgrading bool
+	pid       int
+}
+
+// NewUpgrader returns new Upgrader which passes descriptors of given
+// Listeners. Upgrader uses Clock and name from given Context.
+func NewUpgrader(ctx context.Context, listeners *Listeners, config UpgraderConfig) (u *Upgrader) {
+	if len(config.Signals) == 0 {
+		config.Signals = []os.Signal{syscall.SIGHUP}
+	}
+	if config.ReadyTimeout <= 0 {
+		config.ReadyTimeout = time.Second * 30
+	}
+	if config.Args == nil && len(os.Args) > 0 {
+		config.Args = os.Args[1:]
+	}
+	if config.Env == nil {
+		config.Env = os.Environ()
+	}
+	if config.Stdout == nil {
+		config.Stdout = os.Stdout
+	}
+	if config.Stderr == nil {
+		config.Stderr = os.Stderr
+	}
+	return &Upgrader{
+		Control:   NewControl(ctx),
+		listeners: listeners,
+		config:    config,
+		clock:     ClockFrom(ctx),
+	}
+}
+
+// Open starts listening of signals. Repeated calls return the result of the
+// first call.
+func (u *Upgrader) Open() (err error) {
+	return u.openWith(u.open)
+}
+
+func (u *Upgrader) open() (err error) {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, u.config.Signals...)
+	u.Go(func(ctx context.Context) (err error) {
+		defer signal.Stop(sigChan)
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-sigChan:
+				u.Go(func(ctx context.Context) (err error) {
+					if err := u.Upgrade(); err != nil {
+						LoggerFrom(u.Ctx()).LogAttrs(ctx, slog.LevelError, "upgrade failed",
+							slog.String(LogError, err.Error()),
+						)
+					}
+					return nil
+				})
+			}
+		}
+	})
+	return nil
+}
+
+// Pid returns process ID of new process after successful upgrade
+func (u *Upgrader) Pid() (pid int) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.pid
+}
+
+// Upgrade starts new process and blocks until it reports readiness. After
+// successful upgrade Upgrader exits.
+func (u *Upgrader) Upgrade() (err error) {
+	u.mu.Lock()
+	if u.upgrading || u.pid != 0 {
+		u.mu.Unlock()
+		return ErrUpgradeInProgress
+	}
+	u.upgrading = true
+	u.mu.Unlock()
+	defer func() {
+		u.mu.Lock()
+		u.upgrading = false
+		u.mu.Unlock()
+	}()
+	if !u.IsOpen() || u.IsClosed() {
+		return ErrUpgradeAborted
+	}
+	pid, err := u.start()
+	if err != nil {
+		return err
+	}
+	u.mu.Lock()
+	u.pid = pid
+	u.mu.Unlock()
+	u.cancel()
+	return nil
+}
+
+// start starts new process and waits for its readiness
+func (u *Upgrader) start() (pid int, err error) {
+	dir, err := os.MkdirTemp("", "upgrade")
+	if err != nil {
+		return 0, err
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	files, err := u.listeners.Files()
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}()
+	path := u.config.Path
+	if path == "" {
+		if path, err = os.Executable(); err != nil {
+			return 0, err
+		}
+	}
+	cmd := exec.Command(path, u.config.Args...)
+	cmd.Env = append(upgradeEnv(u.config.Env),
+		"NOTIFY_SOCKET="+socket,
+		"LISTEN_FDS="+strconv.Itoa(len(files)),
+		"LISTEN_FDNAMES="+strings.Join(u.listeners.Names(), ":"),
+	)
+	cmd.ExtraFiles = files
+	cmd.Stdout = u.config.Stdout
+	cmd.Stderr = u.config.Stderr
+	if err = cmd.Start(); err != nil {
+		return 0, err
+	}
+	exitChan := make(chan error, 1)
+	go func() {
+		// reaps new process if it exits before current
+		exitChan <- cmd.Wait()
+	}()
+	readyChan := make(chan error, 1)
+	go func() {
+		readyChan <- waitReady(conn)
+	}()
+
+	timer := u.clock.NewTimer(u.config.ReadyTimeout)
+	defer timer.Stop()
+	select {
+	case err = <-readyChan:
+	case err = <-exitChan:
+		if err == nil {
+			err = errors.New("upgraded process exited")
+		}
+	case <-timer.C():
+		err = ErrUpgradeNotReady
+	case <-u.Ctx().Done():
+		err = ErrUpgradeAborted
+	}
+	conn.Close()
+	if err != nil {
+		cmd.Process.Kill()
+		return 0, err
+	}
+	return cmd.Process.Pid, nil
+}
+
+// waitReady reads notifications until "READY=1"
+func waitReady(conn *net.UnixConn) (err error) {
+	buf := make([]byte, 4096)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return err
+		}
+		for _, line := range bytes.Split(buf[:n], []byte("\n")) {
+			if string(line) == "READY=1" {
+				return nil
+			}
+		}
+	}
+}
+
+// upgradeEnv removes notify and socket activation variables
+func upgradeEnv(env []string) (res []string) {
+	for _, kv := range env {
+		switch strings.SplitN(kv, "=", 2)[0] {
+		case "NOTIFY_SOCKET", "LISTEN_PID", "LISTEN_FDS", "LISTEN_FDNAMES":
+			continue
+		}
+		res = append(res, kv)
+	}
+	return res
+}
@@ -0,0 +1,137 @@
+//go:build !windows
+
+package supervisor_test
+
+import (
+	"context"
+	"github.com/akaspin/supervisor"
+	"github.com/akaspin/supervisor/supervisortest"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+const upgraderHelperEnv = "SUPERVISOR_UPGRADER_HELPER"
+
+// TestUpgraderHelper is run as upgraded process by TestUpgrader
+func TestUpgraderHelper(t *testing.T) {
+	mode := os.Getenv(upgraderHelperEnv)
+	if mode == "" {
+		t.Skip("helper process")
+	}
+	if mode == "exit" {
+		os.Exit(1)
+	}
+	l := supervisor.NewListeners(context.Background(), supervisor.ListenerConfig{
+		Name: "http",
+	})
+	s := supervisor.NewSystemd(context.Background(), l, supervisor.SystemdConfig{})
+	if err := s.Open(); err != nil {
+		os.Exit(1)
+	}
+	ln, err := l.Listener("http")
+	if err != nil {
+		os.Exit(1)
+	}
+	conn, err := ln.Accept()
+	if err != nil {
+		os.Exit(1)
+	}
+	conn.Write([]byte("upgraded"))
+	conn.Close()
+	ln.Close()
+	s.Close()
+	s.Wait()
+}
+
+func newUpgrader(ctx context.Context, listeners *supervisor.Listeners, mode string) *supervisor.Upgrader {
+	return supervisor.NewUpgrader(ctx, listeners, supervisor.UpgraderConfig{
+		ReadyTimeout: time.Second * 10,
+		Path:         os.Args[0],
+		Args:         []string{"-test.run=TestUpgraderHelper"},
+		Env:          append(os.Environ(), upgraderHelperEnv+"="+mode),
+		Stdout:       io.Discard,
+		Stderr:       io.Discard,
+	})
+}
+
+func TestUpgrader(t *testing.T) {
+	t.Run("upgrade", func(t *testing.T) {
+		l := supervisor.NewListeners(context.Background(), supervisor.ListenerConfig{
+			Name:    "http",
+			Address: "127.0.0.1:0",
+		})
+		u := newUpgrader(context.Background(), l, "serve")
+		sv := supervisor.NewChain(context.Background(), l, u)
+		assert.NoError(t, sv.Open())
+		ln, err := l.Listener("http")
+		assert.NoError(t, err)
+		addr := ln.Addr().String()
+		ln.Close()
+
+		assert.NoError(t, u.Upgrade())
+		assert.NotZero(t, u.Pid())
+		assert.Equal(t, supervisor.ErrUpgradeInProgress, u.Upgrade())
+		assert.NoError(t, sv.Wait())
+
+		// listener is served by new process after current tree is closed
+		conn, err := net.Dial("tcp", addr)
+		assert.NoError(t, err)
+		defer conn.Close()
+		conn.SetReadDeadline(time.Now().Add(time.Second * 5))
+		data, err := io.ReadAll(conn)
+		assert.NoError(t, err)
+		assert.Equal(t, "upgraded", string(data))
+	})
+	t.Run("signal", func(t *testing.T) {
+		buf := &logBuffer{}
+		ctx := supervisor.WithLogger(context.Background(), newTestLogger(buf))
+		l := supervisor.NewListeners(ctx, supervisor.ListenerConfig{
+			Name:    "http",
+			Address: "127.0.0.1:0",
+		})
+		u := newUpgrader(supervisor.WithName(ctx, "upgrader"), l, "exit")
+		sv := supervisor.NewChain(supervisor.WithName(ctx, "root"), l, u)
+		assert.NoError(t, sv.Open())
+		assert.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+		deadline := time.Now().Add(time.Second * 10)
+		for time.Now().Before(deadline) && len(buf.lines(`"upgrade`)) == 0 {
+			time.Sleep(time.Millisecond * 10)
+		}
+		assert.Equal(t, []string{
+			`level=ERROR msg="upgrade failed" component=upgrader path=root/upgrader error="exit status 1"`,
+		}, buf.lines(`"upgrade`))
+		assert.Equal(t, supervisor.StateOpen, sv.State())
+		assert.NoError(t, sv.Close())
+		assert.NoError(t, sv.Wait())
+		assert.Equal(t, supervisor.ErrUpgradeAborted, u.Upgrade())
+	})
+	t.Run("exit", func(t *testing.T) {
+		l := supervisor.NewListeners(context.Background(), supervisor.ListenerConfig{
+			Name:    "http",
+			Address: "127.0.0.1:0",
+		})
+		u := newUpgrader(context.Background(), l, "exit")
+		sv := supervisor.NewChain(context.Background(), l, u)
+		assert.NoError(t, sv.Open())
+		err := u.Upgrade()
+		assert.Error(t, err)
+		assert.True(t, strings.Contains(err.Error(), "exit status 1"), err.Error())
+		assert.Zero(t, u.Pid())
+		assert.Equal(t, supervisor.StateOpen, sv.State())
+		assert.NoError(t, sv.Close())
+		assert.NoError(t, sv.Wait())
+	})
+}
+
+func TestUpgrader_Conformance(t *testing.T) {
+	supervisortest.Conformance(t, func() supervisor.Component {
+		ctx := context.Background()
+		return newUpgrader(ctx, supervisor.NewListeners(ctx), "exit")
+	})
+}
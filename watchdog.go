@@ -0,0 +1,71 @@
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrStalled is returned by Watchdog if it is not kicked in time
+	ErrStalled = errors.New("watchdog is not kicked in time")
+)
+
+// Watchdog fails with ErrStalled if it is not kicked within interval. Use
+// Watchdog in Group next to component which should make progress and call
+// Kick() on each progress step.
+type Watchdog struct {
+	*Control
+	interval time.Duration
+	clock    Clock
+	kicks    chan struct{}
+}
+
+// NewWatchdog returns new Watchdog with given interval. Watchdog uses Clock
+// and name from given Context.
+func NewWatchdog(ctx context.Context, interval time.Duration) (w *Watchdog) {
+	return &Watchdog{
+		Control:  NewControl(ctx),
+		interval: interval,
+		clock:    ClockFrom(ctx),
+		kicks:    make(chan struct{}, 1),
+	}
+}
+
+// Open opens Watchdog and starts interval. Repeated calls return the result
+// of the first call.
+func (w *Watchdog) Open() (err error) {
+	return w.openWith(w.open)
+}
+
+func (w *Watchdog) open() (err error) {
+	w.Go(func(ctx context.Context) (err error) {
+		timer := w.clock.NewTimer(w.interval)
+		defer timer.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-w.kicks:
+				if !timer.Stop() {
+					select {
+					case <-timer.C():
+					default:
+					}
+				}
+				timer.Reset(w.interval)
+			case <-timer.C():
+				return ErrStalled
+			}
+		}
+	})
+	return nil
+}
+
+// Kick restarts interval
+func (w *Watchdog) Kick() {
+	select {
+	case w.kicks <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,42 @@
+package supervisor_test
+
+import (
+	"context"
+	"github.com/akaspin/supervisor"
+	"github.com/akaspin/supervisor/supervisortest"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestWatchdog(t *testing.T) {
+	t.Run("stall", func(t *testing.T) {
+		w := supervisor.NewWatchdog(supervisor.WithName(context.Background(), "watchdog"), time.Millisecond*50)
+		c1 := supervisortest.NewComponent("1", nil)
+		sv := supervisor.NewGroup(context.Background(), c1, w)
+		assert.NoError(t, sv.Open())
+		for i := 0; i < 10; i++ {
+			time.Sleep(time.Millisecond * 10)
+			w.Kick()
+		}
+		assert.Equal(t, supervisor.StateOpen, sv.State())
+		assert.Equal(t, supervisor.ErrStalled, sv.Wait())
+		assert.Equal(t, "watchdog", sv.ShutdownReport().Initiator)
+		assert.Equal(t, 1, c1.Calls(supervisor.PhaseClose))
+	})
+	t.Run("close", func(t *testing.T) {
+		clock := supervisortest.NewClock(time.Now())
+		w := supervisor.NewWatchdog(supervisor.WithClock(context.Background(), clock), time.Second)
+		assert.NoError(t, w.Open())
+		clock.BlockUntil(1)
+		clock.Advance(time.Millisecond * 999)
+		assert.NoError(t, w.Close())
+		assert.NoError(t, w.Wait())
+	})
+}
+
+func TestWatchdog_Conformance(t *testing.T) {
+	supervisortest.Conformance(t, func() supervisor.Component {
+		return supervisor.NewWatchdog(context.Background(), time.Second)
+	})
+}